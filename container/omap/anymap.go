@@ -0,0 +1,87 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package omap
+
+import (
+	"iter"
+
+	"github.com/layer8co/toolbox/container/omap/internal/anymap"
+)
+
+func init() {
+	anymap.Of = anyMapOf
+}
+
+// anyMapper is implemented by every Map instantiation,
+// and by pointers to them.
+type anyMapper interface {
+	anyMap() anymap.Map
+}
+
+// anyMapPtrer is implemented by pointers to every Map instantiation.
+type anyMapPtrer interface {
+	anyMapPtr() anymap.Map
+}
+
+func anyMapOf(v any) (anymap.Map, bool) {
+	switch v := v.(type) {
+	case anyMapPtrer:
+		return v.anyMapPtr(), true
+	case anyMapper:
+		return v.anyMap(), true
+	}
+	return nil, false
+}
+
+func (m Map[K, V]) anyMap() anymap.Map {
+	return (*anyView[K, V])(&m)
+}
+
+func (m *Map[K, V]) anyMapPtr() anymap.Map {
+	return (*anyView[K, V])(m)
+}
+
+// anyView implements [anymap.Map] for a Map.
+type anyView[K comparable, V any] Map[K, V]
+
+func (v *anyView[K, V]) m() *Map[K, V] {
+	return (*Map[K, V])(v)
+}
+
+func (v *anyView[K, V]) IsNil() bool {
+	return v.m().IsNil()
+}
+
+func (v *anyView[K, V]) Len() int {
+	return v.m().Len()
+}
+
+func (v *anyView[K, V]) All() iter.Seq2[any, any] {
+	return func(yield func(any, any) bool) {
+		for k, val := range v.m().All() {
+			if !yield(k, val) {
+				return
+			}
+		}
+	}
+}
+
+func (v *anyView[K, V]) Init() {
+	v.m().init()
+}
+
+func (v *anyView[K, V]) Set(decodeKey, decodeVal func(p any) error) error {
+	var key K
+	var val V
+	err := decodeKey(&key)
+	if err != nil {
+		return err
+	}
+	err = decodeVal(&val)
+	if err != nil {
+		return err
+	}
+	v.m().Set(key, val)
+	return nil
+}
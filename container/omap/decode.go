@@ -0,0 +1,105 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package omap
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// DuplicateKeyError is returned when decoding with [DuplicateKeysError]
+// encounters a key that has already been seen.
+type DuplicateKeyError struct {
+	Key string
+
+	// Offset is the number of bytes of JSON input
+	// read before the error occurred, like [json.SyntaxError.Offset].
+	Offset int64
+
+	// Line and Column are the 1-based position
+	// of the duplicate key in YAML input.
+	Line   int
+	Column int
+}
+
+func (e *DuplicateKeyError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf(
+			"duplicate key %q at line %d, column %d",
+			e.Key, e.Line, e.Column,
+		)
+	}
+	return fmt.Sprintf("duplicate key %q at offset %d", e.Key, e.Offset)
+}
+
+// checkDecodeConfig returns an error if c can't be used
+// to decode a map with values of type V.
+func checkDecodeConfig[V any](c *decodeConfig) error {
+	if c.duplicateKeys == DuplicateKeysCollect {
+		if t := reflect.TypeFor[V](); t.Kind() != reflect.Slice {
+			return fmt.Errorf(
+				"DuplicateKeysCollect requires a slice value type, got %v",
+				t,
+			)
+		}
+	}
+	return nil
+}
+
+// decodeEntry decodes the value of key using decode
+// and stores it in the map according to the duplicate key policy of c.
+//
+// seen holds the keys decoded so far from the same object,
+// so that keys already in the map before decoding aren't duplicates.
+// skip is called instead of decode when the value is to be ignored,
+// and dup is called to create the error for [DuplicateKeysError].
+func (m *Map[K, V]) decodeEntry(
+	c *decodeConfig,
+	seen map[K]struct{},
+	key K,
+	decode func(v any) error,
+	skip func() error,
+	dup func() error,
+) error {
+
+	_, has := seen[key]
+	seen[key] = struct{}{}
+
+	switch c.duplicateKeys {
+
+	case DuplicateKeysFirst:
+		if has {
+			return skip()
+		}
+
+	case DuplicateKeysError:
+		if has {
+			return dup()
+		}
+
+	case DuplicateKeysCollect:
+		var val V
+		if has {
+			val, _ = m.Get(key)
+		}
+		s := reflect.ValueOf(&val).Elem()
+		elem := reflect.New(s.Type().Elem())
+		err := decode(elem.Interface())
+		if err != nil {
+			return err
+		}
+		s.Set(reflect.Append(s, elem.Elem()))
+		m.Set(key, val)
+		return nil
+	}
+
+	var val V
+	err := decode(&val)
+	if err != nil {
+		return err
+	}
+
+	m.Set(key, val)
+	return nil
+}
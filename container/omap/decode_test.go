@@ -0,0 +1,196 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package omap_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/layer8co/toolbox/container/omap"
+)
+
+func TestDuplicateKeys(t *testing.T) {
+
+	const (
+		inJSON = `{"a": 1, "b": 2, "a": 3}`
+		inYAML = "a: 1\nb: 2\na: 3\n"
+	)
+
+	tests := []struct {
+		name    string
+		policy  omap.DuplicateKeys
+		want    string
+		wantErr string
+	}{
+		{"last", omap.DuplicateKeysLast, "omap[a:3 b:2]", ""},
+		{"first", omap.DuplicateKeysFirst, "omap[a:1 b:2]", ""},
+		{"collect", omap.DuplicateKeysCollect, "omap[a:[1 3] b:[2]]", ""},
+		{"error", omap.DuplicateKeysError, "", `duplicate key "a"`},
+	}
+
+	decoders := []struct {
+		name   string
+		decode func(m any, policy omap.DuplicateKeys) error
+	}{
+		{
+			"json",
+			func(m any, policy omap.DuplicateKeys) error {
+				return m.(decoder).DecodeJSON(strings.NewReader(inJSON), omap.WithDuplicateKeys(policy))
+			},
+		},
+		{
+			"yaml",
+			func(m any, policy omap.DuplicateKeys) error {
+				return m.(decoder).DecodeYAML(strings.NewReader(inYAML), omap.WithDuplicateKeys(policy))
+			},
+		},
+	}
+
+	for _, d := range decoders {
+		for _, test := range tests {
+			t.Run(d.name+"-"+test.name, func(t *testing.T) {
+
+				var m fmt.Stringer
+				if test.policy == omap.DuplicateKeysCollect {
+					m = new(omap.Map[string, []int])
+				} else {
+					m = new(omap.Map[string, int])
+				}
+
+				err := d.decode(m, test.policy)
+
+				if test.wantErr != "" {
+					var dupErr *omap.DuplicateKeyError
+					if !errors.As(err, &dupErr) || !strings.Contains(err.Error(), test.wantErr) {
+						t.Fatalf("incorrect error: want %q, got %v", test.wantErr, err)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if diff := cmp.Diff(test.want, m.String()); diff != "" {
+					t.Errorf("incorrect result (-want +got):\n%s", diff)
+				}
+			})
+		}
+	}
+}
+
+// TestDuplicateKeysOverDefaults checks that keys already in the map
+// before decoding aren't treated as duplicates.
+func TestDuplicateKeysOverDefaults(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		policy omap.DuplicateKeys
+		want   string
+	}{
+		{"last", omap.DuplicateKeysLast, "omap[a:[2] b:[1]]"},
+		{"first", omap.DuplicateKeysFirst, "omap[a:[2] b:[1]]"},
+		{"collect", omap.DuplicateKeysCollect, "omap[a:[2] b:[1]]"},
+		{"error", omap.DuplicateKeysError, "omap[a:[2] b:[1]]"},
+	}
+
+	decoders := []struct {
+		name   string
+		decode func(m *omap.Map[string, []int], val string, policy omap.DuplicateKeys) error
+	}{
+		{
+			"json",
+			func(m *omap.Map[string, []int], val string, policy omap.DuplicateKeys) error {
+				return m.DecodeJSON(strings.NewReader(`{"a": `+val+`}`), omap.WithDuplicateKeys(policy))
+			},
+		},
+		{
+			"yaml",
+			func(m *omap.Map[string, []int], val string, policy omap.DuplicateKeys) error {
+				return m.DecodeYAML(strings.NewReader("a: "+val+"\n"), omap.WithDuplicateKeys(policy))
+			},
+		},
+	}
+
+	for _, d := range decoders {
+		for _, test := range tests {
+			t.Run(d.name+"-"+test.name, func(t *testing.T) {
+
+				m := omap.New[string, []int]()
+				m.Set("a", []int{1})
+				m.Set("b", []int{1})
+
+				// Collected values are decoded as elements of the slice.
+				val := "[2]"
+				if test.policy == omap.DuplicateKeysCollect {
+					val = "2"
+				}
+
+				err := d.decode(&m, val, test.policy)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if diff := cmp.Diff(test.want, m.String()); diff != "" {
+					t.Errorf("incorrect result (-want +got):\n%s", diff)
+				}
+			})
+		}
+	}
+}
+
+func TestDuplicateKeyErrorPosition(t *testing.T) {
+
+	var m omap.Map[string, int]
+
+	err := m.DecodeYAML(strings.NewReader("a: 1\nb: 2\n\na: 3\n"), omap.WithStrict())
+	want := `duplicate key "a" at line 4, column 1`
+	if err == nil || err.Error() != want {
+		t.Errorf("incorrect error: want %q, got %v", want, err)
+	}
+
+	m = omap.Map[string, int]{}
+	err = m.DecodeJSON(strings.NewReader(`{"a": 1, "a": 2}`), omap.WithStrict())
+	want = `duplicate key "a" at offset 12`
+	if err == nil || err.Error() != want {
+		t.Errorf("incorrect error: want %q, got %v", want, err)
+	}
+}
+
+func TestStrictValues(t *testing.T) {
+
+	type T struct {
+		X int `json:"x" yaml:"x"`
+	}
+
+	var m omap.Map[string, T]
+
+	if err := m.DecodeJSON(strings.NewReader(`{"a": {"y": 1}}`), omap.WithStrict()); err == nil {
+		t.Error("json: expected unknown field error")
+	}
+
+	if err := m.DecodeYAML(strings.NewReader("a: {y: 1}\n"), omap.WithStrict()); err == nil {
+		t.Error("yaml: expected unknown field error")
+	}
+
+	if err := m.DecodeYAML(strings.NewReader("a: {x: 1}\n"), omap.WithStrict()); err != nil {
+		t.Errorf("yaml: unexpected error: %v", err)
+	}
+}
+
+func TestCollectRequiresSlice(t *testing.T) {
+	var m omap.Map[string, int]
+	err := m.DecodeJSON(strings.NewReader(`{}`), omap.WithDuplicateKeys(omap.DuplicateKeysCollect))
+	if err == nil {
+		t.Error("expected error for non-slice value type")
+	}
+}
+
+type decoder interface {
+	DecodeJSON(r io.Reader, options ...omap.DecodeOption) error
+	DecodeYAML(r io.Reader, options ...omap.DecodeOption) error
+}
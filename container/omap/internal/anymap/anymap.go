@@ -0,0 +1,31 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package anymap lets the encoding packages of omap
+// handle nested maps regardless of their key and value types.
+package anymap
+
+import (
+	"iter"
+)
+
+// Map is an omap.Map with its types erased.
+type Map interface {
+	IsNil() bool
+	Len() int
+	All() iter.Seq2[any, any]
+
+	// Init initializes the map if it's nil.
+	Init()
+
+	// Set decodes an entry by passing pointers to a new key and value
+	// to decodeKey and decodeVal, and sets it in the map.
+	Set(decodeKey, decodeVal func(p any) error) error
+}
+
+// Of returns v as a [Map] if it's an omap.Map or a pointer to one.
+// Maps must be passed by pointer to be modified.
+//
+// It's set by package omap,
+// which is always imported by the users of this package.
+var Of func(v any) (Map, bool)
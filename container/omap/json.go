@@ -4,76 +4,218 @@
 package omap
 
 import (
+	"bufio"
 	"bytes"
+	"encoding"
 	"encoding/json"
 	"fmt"
+	"io"
+	"reflect"
 	"strconv"
-
-	"github.com/layer8co/toolbox/must"
+	"strings"
 )
 
+// MarshalJSON implements [json.Marshaler]
+// using the default options of [Map.EncodeJSON].
 func (m Map[K, V]) MarshalJSON() ([]byte, error) {
+	b := new(bytes.Buffer)
+	err := m.EncodeJSON(b)
+	if err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// UnmarshalJSON implements [json.Unmarshaler]
+// using the default options of [Map.DecodeJSON].
+func (m *Map[K, V]) UnmarshalJSON(b []byte) error {
+	return m.DecodeJSON(bytes.NewReader(b))
+}
+
+// EncodeJSON writes the JSON encoding of the map to w,
+// one entry at a time.
+// Nested maps are streamed as well.
+//
+// Keys are encoded like the keys of builtin maps by [json.Marshal]:
+// string keys are used directly, [encoding.TextMarshaler] keys are marshaled,
+// and integer keys are formatted as decimal strings.
+//
+// Unlike [json.Encoder.Encode], no newline is written after the object.
+func (m Map[K, V]) EncodeJSON(w io.Writer, options ...EncodeOption) error {
+
+	c := &encodeConfig{
+		escapeHTML: true,
+	}
+	for _, fn := range options {
+		fn(c)
+	}
+
+	e := &jsonEncoder{
+		w:      bufio.NewWriter(w),
+		config: c,
+	}
+	e.enc = json.NewEncoder(&e.buf)
+	e.enc.SetEscapeHTML(c.escapeHTML)
+
+	err := m.encodeJSON(e, 0)
+	if err != nil {
+		return err
+	}
+
+	return e.w.Flush()
+}
+
+// DecodeJSON reads the next JSON-encoded object from r
+// and stores its entries in the map, one entry at a time.
+// Nested maps are streamed as well.
+//
+// Keys are decoded like the keys of builtin maps by [json.Unmarshal]:
+// [encoding.TextUnmarshaler] keys are unmarshaled,
+// string keys are used directly, and integer keys are parsed.
+//
+// Since the decoder reads ahead, r might be read past the end of the object.
+func (m *Map[K, V]) DecodeJSON(r io.Reader, options ...DecodeOption) error {
+
+	c := &decodeConfig{}
+	for _, fn := range options {
+		fn(c)
+	}
+
+	dec := json.NewDecoder(r)
+	if c.useNumber {
+		dec.UseNumber()
+	}
+	if c.disallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+
+	return m.decodeJSON(dec, c)
+}
+
+// jsonStreamer is implemented by [Map]
+// so that nested maps can be streamed
+// instead of going through [json.Marshaler] and [json.Unmarshaler].
+type jsonStreamer interface {
+	encodeJSON(e *jsonEncoder, depth int) error
+}
+
+type jsonDestreamer interface {
+	decodeJSON(dec *json.Decoder, c *decodeConfig) error
+}
+
+type jsonEncoder struct {
+	w      *bufio.Writer
+	buf    bytes.Buffer
+	enc    *json.Encoder // Writes to buf.
+	config *encodeConfig
+}
+
+func (m Map[K, V]) encodeJSON(e *jsonEncoder, depth int) error {
 
 	if m.IsNil() {
-		return []byte("null"), nil
+		e.w.WriteString("null")
+		return nil
 	}
 
-	b := new(bytes.Buffer)
-	b.WriteByte('{')
+	e.w.WriteByte('{')
 
 	for i, t := range m.s {
 
 		if i > 0 {
-			b.WriteByte(',')
+			e.w.WriteByte(',')
 		}
+		e.newline(depth + 1)
 
-		key, err := json.Marshal(t.key)
+		key, err := marshalKey(t.key)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
-		if len(key) == 0 || key[0] != '"' {
-			s := itoa(t.key)
-			if s == "" {
-				return nil, fmt.Errorf("unsupported key type %T", t.key)
-			}
-			key, err = json.Marshal(s)
-			if err != nil {
-				return nil, err
-			}
+		err = e.encode(key, depth+1)
+		if err != nil {
+			return err
 		}
 
-		b.Write(key)
-		b.WriteByte(':')
+		e.w.WriteByte(':')
+		if e.config.indent != "" || e.config.prefix != "" {
+			e.w.WriteByte(' ')
+		}
 
-		val, err := json.Marshal(t.val)
+		if s, ok := any(t.val).(jsonStreamer); ok && !isNil(t.val) {
+			err = s.encodeJSON(e, depth+1)
+		} else {
+			err = e.encode(t.val, depth+1)
+		}
 		if err != nil {
-			return nil, err
+			return err
 		}
+	}
 
-		b.Write(val)
+	if len(m.s) > 0 {
+		e.newline(depth)
 	}
 
-	b.WriteByte('}')
-	return b.Bytes(), nil
+	return e.w.WriteByte('}')
 }
 
-func (m *Map[K, V]) UnmarshalJSON(b []byte) error {
+// encode writes the JSON encoding of v,
+// indented as if it was depth levels deep.
+func (e *jsonEncoder) encode(v any, depth int) error {
+	e.buf.Reset()
+	if e.config.indent != "" || e.config.prefix != "" {
+		e.enc.SetIndent(e.config.prefix+strings.Repeat(e.config.indent, depth), e.config.indent)
+	}
+	err := e.enc.Encode(v)
+	if err != nil {
+		return err
+	}
+	_, err = e.w.Write(bytes.TrimSuffix(e.buf.Bytes(), []byte{'\n'}))
+	return err
+}
 
-	m.init()
+func (e *jsonEncoder) newline(depth int) {
+	if e.config.indent == "" && e.config.prefix == "" {
+		return
+	}
+	e.w.WriteByte('\n')
+	e.w.WriteString(e.config.prefix)
+	for range depth {
+		e.w.WriteString(e.config.indent)
+	}
+}
 
-	dec := json.NewDecoder(bytes.NewReader(b))
+func (m *Map[K, V]) decodeJSON(dec *json.Decoder, c *decodeConfig) error {
+
+	err := checkDecodeConfig[V](c)
+	if err != nil {
+		return err
+	}
 
 	t, err := dec.Token()
 	if err != nil {
 		return err
 	}
 
+	if t == nil {
+		return nil
+	}
+
 	delim, ok := t.(json.Delim)
 	if !ok || delim != '{' {
 		return fmt.Errorf("expected '{', got %v", t)
 	}
 
+	return m.decodeJSONObject(dec, c)
+}
+
+// decodeJSONObject decodes the entries of an object
+// whose opening '{' has already been read.
+func (m *Map[K, V]) decodeJSONObject(dec *json.Decoder, c *decodeConfig) error {
+
+	m.init()
+
+	seen := make(map[K]struct{})
+
 	for dec.More() {
 
 		t, err := dec.Token()
@@ -87,9 +229,8 @@ func (m *Map[K, V]) UnmarshalJSON(b []byte) error {
 		}
 
 		var key K
-		var val V
 
-		err = json.Unmarshal(must.Get(json.Marshal(keyStr)), &key)
+		err = unmarshalKey(keyStr, &key)
 		if err != nil {
 			return fmt.Errorf(
 				"could not decode key %q into %T: %w",
@@ -97,19 +238,41 @@ func (m *Map[K, V]) UnmarshalJSON(b []byte) error {
 			)
 		}
 
-		if err := dec.Decode(&val); err != nil {
+		err = m.decodeEntry(
+			c,
+			seen,
+			key,
+			func(v any) error {
+				switch v := v.(type) {
+				case jsonDestreamer:
+					return v.decodeJSON(dec, c)
+				case *any:
+					return decodeJSONAny(dec, c, v)
+				}
+				return dec.Decode(v)
+			},
+			func() error {
+				var raw json.RawMessage
+				return dec.Decode(&raw)
+			},
+			func() error {
+				return &DuplicateKeyError{
+					Key:    keyStr,
+					Offset: dec.InputOffset(),
+				}
+			},
+		)
+		if err != nil {
 			return err
 		}
-
-		m.Set(key, val)
 	}
 
-	t, err = dec.Token()
+	t, err := dec.Token()
 	if err != nil {
 		return err
 	}
 
-	delim, ok = t.(json.Delim)
+	delim, ok := t.(json.Delim)
 	if !ok || delim != '}' {
 		return fmt.Errorf("expected '}', got %v", t)
 	}
@@ -117,31 +280,105 @@ func (m *Map[K, V]) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-func itoa[T any](v T) string {
-	switch v := any(v).(type) {
-	case int:
-		return strconv.FormatInt(int64(v), 10)
-	case int8:
-		return strconv.FormatInt(int64(v), 10)
-	case int16:
-		return strconv.FormatInt(int64(v), 10)
-	case int32:
-		return strconv.FormatInt(int64(v), 10)
-	case int64:
-		return strconv.FormatInt(v, 10)
-	case uint:
-		return strconv.FormatUint(uint64(v), 10)
-	case uintptr:
-		return strconv.FormatUint(uint64(v), 10)
-	case uint8:
-		return strconv.FormatUint(uint64(v), 10)
-	case uint16:
-		return strconv.FormatUint(uint64(v), 10)
-	case uint32:
-		return strconv.FormatUint(uint64(v), 10)
-	case uint64:
-		return strconv.FormatUint(v, 10)
+// decodeJSONAny decodes the next JSON value into v
+// like [json.Decoder.Decode] would,
+// except that objects are decoded into ordered Map[string, any] values.
+func decodeJSONAny(dec *json.Decoder, c *decodeConfig, v *any) error {
+
+	t, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	delim, ok := t.(json.Delim)
+	if !ok {
+		*v = t
+		return nil
+	}
+
+	switch delim {
+
+	case '{':
+		m := New[string, any]()
+		err := m.decodeJSONObject(dec, c.nested())
+		if err != nil {
+			return err
+		}
+		*v = m
+
+	case '[':
+		s := []any{}
+		for dec.More() {
+			var e any
+			err := decodeJSONAny(dec, c, &e)
+			if err != nil {
+				return err
+			}
+			s = append(s, e)
+		}
+		_, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		*v = s
+
+	default:
+		return fmt.Errorf("unexpected %v", delim)
+	}
+
+	return nil
+}
+
+// marshalKey returns the string used as the JSON object key for key.
+func marshalKey[K any](key K) (string, error) {
+	v := reflect.ValueOf(key)
+	if v.Kind() == reflect.String {
+		return v.String(), nil
+	}
+	if tm, ok := any(key).(encoding.TextMarshaler); ok {
+		if v.Kind() == reflect.Pointer && v.IsNil() {
+			return "", nil
+		}
+		b, err := tm.MarshalText()
+		return string(b), err
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("unsupported key type %T", key)
+}
+
+func isNil(v any) bool {
+	r := reflect.ValueOf(v)
+	return r.Kind() == reflect.Pointer && r.IsNil()
+}
+
+// unmarshalKey stores the key decoded from the JSON object key s in key.
+func unmarshalKey[K any](s string, key *K) error {
+	if tu, ok := any(key).(encoding.TextUnmarshaler); ok {
+		return tu.UnmarshalText([]byte(s))
+	}
+	v := reflect.ValueOf(key).Elem()
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
 	default:
-		return ""
+		return fmt.Errorf("unsupported key type %T", *key)
 	}
+	return nil
 }
@@ -0,0 +1,113 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package omap_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/layer8co/toolbox/container/omap"
+)
+
+func TestEncodeJSON(t *testing.T) {
+
+	inner := omap.New[string, any]()
+	inner.Set("<b>", []int{1, 2})
+	inner.Set("a", omap.New[int, int]())
+
+	m := omap.New[string, any]()
+	m.Set("z", 1)
+	m.Set("y", inner)
+	m.Set("x", nil)
+
+	tests := []struct {
+		name    string
+		options []omap.EncodeOption
+		want    string
+	}{
+		{
+			"compact",
+			nil,
+			`{"z":1,"y":{"\u003cb\u003e":[1,2],"a":{}},"x":null}`,
+		},
+		{
+			"no-escape",
+			[]omap.EncodeOption{omap.WithEscapeHTML(false)},
+			`{"z":1,"y":{"<b>":[1,2],"a":{}},"x":null}`,
+		},
+		{
+			"indent",
+			[]omap.EncodeOption{omap.WithIndent(">", "\t")},
+			"{\n" +
+				">\t\"z\": 1,\n" +
+				">\t\"y\": {\n" +
+				">\t\t\"\\u003cb\\u003e\": [\n" +
+				">\t\t\t1,\n" +
+				">\t\t\t2\n" +
+				">\t\t],\n" +
+				">\t\t\"a\": {}\n" +
+				">\t},\n" +
+				">\t\"x\": null\n" +
+				">}",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b := new(bytes.Buffer)
+			if err := m.EncodeJSON(b, test.options...); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(test.want, b.String()); diff != "" {
+				t.Errorf("incorrect result (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDecodeJSON(t *testing.T) {
+
+	in := `{"3": {"b": 1, "a": 2}, "1": {}, "2": null}`
+
+	var m omap.Map[int, omap.Map[string, json.Number]]
+	if err := m.DecodeJSON(strings.NewReader(in), omap.WithUseNumber()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "omap[3:omap[b:1 a:2] 1:omap[] 2:omap[]]"
+	got := m.String()
+	if want != got {
+		t.Errorf("incorrect map: want %q, got %q", want, got)
+	}
+}
+
+func TestJSONTextKeys(t *testing.T) {
+
+	m := omap.New[netip.Addr, int]()
+	m.Set(netip.MustParseAddr("10.0.0.2"), 2)
+	m.Set(netip.MustParseAddr("10.0.0.1"), 1)
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"10.0.0.2":2,"10.0.0.1":1}`
+	if diff := cmp.Diff(want, string(b)); diff != "" {
+		t.Errorf("incorrect result (-want +got):\n%s", diff)
+	}
+
+	var m2 omap.Map[netip.Addr, int]
+	if err := json.Unmarshal(b, &m2); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(m.String(), m2.String()); diff != "" {
+		t.Errorf("incorrect round-trip (-want +got):\n%s", diff)
+	}
+}
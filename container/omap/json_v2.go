@@ -0,0 +1,136 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build go1.27 || goexperiment.jsonv2
+
+// encoding/json/v2 is available as of go1.27,
+// and with GOEXPERIMENT=jsonv2 as of go1.25.
+// Files that refer to it must require go1.27 on go1.27 and later,
+// so this file only uses it through the declarations
+// in json_v2_go127.go and json_v2_exp.go.
+
+package omap
+
+import "fmt"
+
+func (m Map[K, V]) marshalJSONTo(enc *v2Encoder) error {
+
+	if m.IsNil() {
+		return enc.WriteToken(v2Null)
+	}
+
+	err := enc.WriteToken(v2BeginObject)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range m.s {
+
+		key, err := marshalKey(t.key)
+		if err != nil {
+			return err
+		}
+
+		err = enc.WriteToken(v2String(key))
+		if err != nil {
+			return err
+		}
+
+		err = v2MarshalEncode(enc, t.val)
+		if err != nil {
+			return err
+		}
+	}
+
+	return enc.WriteToken(v2EndObject)
+}
+
+func (m *Map[K, V]) unmarshalJSONFrom(dec *v2Decoder) error {
+
+	switch k := dec.PeekKind(); k {
+	case 'n':
+		_, err := dec.ReadToken()
+		return err
+	case '{':
+	default:
+		return fmt.Errorf("expected '{', got %v", k)
+	}
+
+	_, err := dec.ReadToken()
+	if err != nil {
+		return err
+	}
+
+	m.init()
+
+	for dec.PeekKind() != '}' {
+
+		t, err := dec.ReadToken()
+		if err != nil {
+			return err
+		}
+
+		keyStr := t.String()
+
+		var key K
+		var val V
+
+		err = unmarshalKey(keyStr, &key)
+		if err != nil {
+			return fmt.Errorf(
+				"could not decode key %q into %T: %w",
+				keyStr, key, err,
+			)
+		}
+
+		if p, ok := any(&val).(*any); ok {
+			err = unmarshalJSONAnyFrom(dec, p)
+		} else {
+			err = v2UnmarshalDecode(dec, &val)
+		}
+		if err != nil {
+			return err
+		}
+
+		m.Set(key, val)
+	}
+
+	_, err = dec.ReadToken()
+	return err
+}
+
+// unmarshalJSONAnyFrom is like [decodeJSONAny]
+// but for [encoding/json/jsontext.Decoder].
+func unmarshalJSONAnyFrom(dec *v2Decoder, v *any) error {
+	switch dec.PeekKind() {
+	case '{':
+		m := New[string, any]()
+		err := m.unmarshalJSONFrom(dec)
+		if err != nil {
+			return err
+		}
+		*v = m
+	case '[':
+		_, err := dec.ReadToken()
+		if err != nil {
+			return err
+		}
+		s := []any{}
+		for dec.PeekKind() != ']' {
+			var e any
+			err := unmarshalJSONAnyFrom(dec, &e)
+			if err != nil {
+				return err
+			}
+			s = append(s, e)
+		}
+		_, err = dec.ReadToken()
+		if err != nil {
+			return err
+		}
+		*v = s
+	default:
+		return v2UnmarshalDecode(dec, v)
+	}
+	return nil
+}
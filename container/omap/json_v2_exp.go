@@ -0,0 +1,41 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build goexperiment.jsonv2 && !go1.27
+
+package omap
+
+import (
+	"encoding/json/jsontext"
+	jsonv2 "encoding/json/v2"
+)
+
+// The encoding/json/v2 API used by json_v2.go; see there.
+type (
+	v2Encoder = jsontext.Encoder
+	v2Decoder = jsontext.Decoder
+)
+
+var (
+	v2Null            = jsontext.Null
+	v2BeginObject     = jsontext.BeginObject
+	v2EndObject       = jsontext.EndObject
+	v2String          = jsontext.String
+	v2MarshalEncode   = jsonv2.MarshalEncode
+	v2UnmarshalDecode = jsonv2.UnmarshalDecode
+)
+
+// MarshalJSONTo implements [jsonv2.MarshalerTo].
+// Keys are encoded like in [Map.EncodeJSON].
+func (m Map[K, V]) MarshalJSONTo(enc *jsontext.Encoder) error {
+	return m.marshalJSONTo(enc)
+}
+
+// UnmarshalJSONFrom implements [jsonv2.UnmarshalerFrom].
+// Keys are decoded like in [Map.DecodeJSON].
+//
+// Note that unless [jsontext.AllowDuplicateNames] is enabled,
+// the decoder rejects duplicate keys.
+func (m *Map[K, V]) UnmarshalJSONFrom(dec *jsontext.Decoder) error {
+	return m.unmarshalJSONFrom(dec)
+}
@@ -0,0 +1,14 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build goexperiment.jsonv2 && !go1.27
+
+package omap_test
+
+import jsonv2 "encoding/json/v2"
+
+// See json_v2.go.
+var (
+	v2Marshal   = jsonv2.Marshal
+	v2Unmarshal = jsonv2.Unmarshal
+)
@@ -0,0 +1,46 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build go1.27 || goexperiment.jsonv2
+
+package omap_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/layer8co/toolbox/container/omap"
+)
+
+func TestJSONv2(t *testing.T) {
+
+	in := `{"z":1,"a":{"y":2,"x":3},"m":null}`
+
+	var m omap.Map[string, any]
+	if err := v2Unmarshal([]byte(in), &m); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "omap[z:1 a:omap[y:2 x:3] m:<nil>]"
+	if diff := cmp.Diff(want, m.String()); diff != "" {
+		t.Errorf("incorrect decoding (-want +got):\n%s", diff)
+	}
+
+	var m2 omap.Map[string, any]
+	if err := v2Unmarshal([]byte(`{"b":1,"a":[true]}`), &m2); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := v2Marshal(m2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(`{"b":1,"a":[true]}`, string(out)); diff != "" {
+		t.Errorf("incorrect round-trip (-want +got):\n%s", diff)
+	}
+
+	if err := v2Unmarshal([]byte(`{"a":1,"a":2}`), &m2); err == nil {
+		t.Error("expected duplicate key error")
+	}
+}
@@ -0,0 +1,359 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package omap
+
+import (
+	"iter"
+	"sync"
+	"time"
+)
+
+// LRU is a least recently used cache that is safe for concurrent use
+// by multiple goroutines.
+//
+// Like [Map], its entries are ordered, from the most recently used
+// to the least recently used, but all of its operations are O(1),
+// except for the ones that iterate over the entries.
+//
+// Entries are evicted when the cache is over capacity,
+// and when they're accessed after their TTL has passed.
+//
+// An LRU must be created using [NewLRU].
+type LRU[K comparable, V any] struct {
+	c LRUConfig[K, V]
+
+	mu    sync.Mutex
+	m     map[K]*lruEntry[K, V]
+	root  lruEntry[K, V] // Sentinel of the list of entries.
+	cost  int64
+	stats LRUStats
+}
+
+// LRUConfig configures an [LRU].
+// The zero value is an unlimited cache.
+type LRUConfig[K comparable, V any] struct {
+
+	// MaxLen is the maximum number of entries.
+	// It's unlimited if not positive.
+	MaxLen int
+
+	// MaxCost is the maximum total cost of the entries.
+	// It's unlimited if not positive.
+	MaxCost int64
+
+	// Cost returns the cost of an entry.
+	// It defaults to 1 for every entry.
+	Cost func(key K, val V) int64
+
+	// TTL is the time to live of entries set using [LRU.Set].
+	// Entries don't expire if it's not positive.
+	TTL time.Duration
+
+	// OnEvict is called after an entry is evicted,
+	// without the lock of the cache held.
+	// It's not called for entries that are deleted or replaced.
+	OnEvict func(key K, val V, reason EvictReason)
+
+	// Now returns the current time.
+	// It defaults to [time.Now].
+	Now func() time.Time
+}
+
+// EvictReason is passed to [LRUConfig.OnEvict].
+type EvictReason uint8
+
+const (
+	// EvictCapacity means the entry was the least recently used one
+	// when the cache was over capacity.
+	// This includes entries whose own cost exceeds [LRUConfig.MaxCost].
+	EvictCapacity EvictReason = iota + 1
+
+	// EvictExpired means the TTL of the entry has passed.
+	EvictExpired
+)
+
+func (r EvictReason) String() string {
+	switch r {
+	case EvictCapacity:
+		return "capacity"
+	case EvictExpired:
+		return "expired"
+	default:
+		return "invalid"
+	}
+}
+
+// LRUStats is returned by [LRU.Stats].
+type LRUStats struct {
+	Hits        uint64
+	Misses      uint64
+	Evictions   uint64 // Evictions due to capacity.
+	Expirations uint64
+}
+
+type lruEntry[K comparable, V any] struct {
+	key        K
+	val        V
+	cost       int64
+	expires    time.Time
+	prev, next *lruEntry[K, V]
+}
+
+type eviction[K comparable, V any] struct {
+	key    K
+	val    V
+	reason EvictReason
+}
+
+func NewLRU[K comparable, V any](c LRUConfig[K, V]) *LRU[K, V] {
+	if c.Cost == nil {
+		c.Cost = func(K, V) int64 { return 1 }
+	}
+	if c.Now == nil {
+		c.Now = time.Now
+	}
+	l := &LRU[K, V]{
+		c: c,
+		m: make(map[K]*lruEntry[K, V]),
+	}
+	l.root.prev = &l.root
+	l.root.next = &l.root
+	return l
+}
+
+// Get returns the value of key and marks it as the most recently used.
+func (l *LRU[K, V]) Get(key K) (val V, has bool) {
+
+	var evicted []eviction[K, V]
+	defer func() { l.evicted(evicted) }()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e := l.lookup(key, &evicted)
+	if e == nil {
+		l.stats.Misses++
+		return val, false
+	}
+
+	l.stats.Hits++
+	l.unlink(e)
+	l.pushFront(e)
+
+	return e.val, true
+}
+
+// Peek is like [LRU.Get], but it doesn't mark the entry as used
+// nor count as a hit or miss.
+func (l *LRU[K, V]) Peek(key K) (val V, has bool) {
+
+	var evicted []eviction[K, V]
+	defer func() { l.evicted(evicted) }()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e := l.lookup(key, &evicted)
+	if e == nil {
+		return val, false
+	}
+	return e.val, true
+}
+
+// Set sets the value of key with the TTL of the config,
+// and marks it as the most recently used.
+func (l *LRU[K, V]) Set(key K, val V) {
+	l.SetWithTTL(key, val, l.c.TTL)
+}
+
+// SetWithTTL is like [LRU.Set], but with the given TTL.
+// The entry doesn't expire if ttl is not positive.
+func (l *LRU[K, V]) SetWithTTL(key K, val V, ttl time.Duration) {
+
+	var evicted []eviction[K, V]
+	defer func() { l.evicted(evicted) }()
+
+	cost := l.c.Cost(key, val)
+
+	var expires time.Time
+	if ttl > 0 {
+		expires = l.c.Now().Add(ttl)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e, has := l.m[key]
+	if has {
+		l.unlink(e)
+		l.cost -= e.cost
+	} else {
+		e = &lruEntry[K, V]{key: key}
+		l.m[key] = e
+	}
+
+	e.val = val
+	e.cost = cost
+	e.expires = expires
+	l.pushFront(e)
+	l.cost += cost
+
+	for l.over() {
+		e := l.root.prev
+		l.remove(e)
+		l.stats.Evictions++
+		evicted = append(evicted, eviction[K, V]{e.key, e.val, EvictCapacity})
+	}
+}
+
+// Delete deletes key from the cache and returns its value.
+func (l *LRU[K, V]) Delete(key K) (val V, has bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e, has := l.m[key]
+	if !has {
+		return val, false
+	}
+	l.remove(e)
+	return e.val, true
+}
+
+// RemoveExpired evicts all of the expired entries.
+// Since expired entries are otherwise only evicted when they're accessed,
+// it can be called periodically to free their memory.
+func (l *LRU[K, V]) RemoveExpired() {
+
+	var evicted []eviction[K, V]
+	defer func() { l.evicted(evicted) }()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.c.Now()
+	for e := l.root.next; e != &l.root; {
+		next := e.next
+		if l.expired(e, now) {
+			l.expire(e, &evicted)
+		}
+		e = next
+	}
+}
+
+// Clear deletes all of the entries.
+func (l *LRU[K, V]) Clear() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	clear(l.m)
+	l.root.prev = &l.root
+	l.root.next = &l.root
+	l.cost = 0
+}
+
+// Len returns the number of entries, including the expired ones
+// that have not been evicted yet.
+func (l *LRU[K, V]) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.m)
+}
+
+// Cost returns the total cost of the entries.
+func (l *LRU[K, V]) Cost() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.cost
+}
+
+func (l *LRU[K, V]) Stats() LRUStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.stats
+}
+
+// Snapshot returns the unexpired entries as an ordered map,
+// from the most recently used to the least recently used.
+func (l *LRU[K, V]) Snapshot() Map[K, V] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	m := New[K, V](len(l.m))
+	now := l.c.Now()
+	for e := l.root.next; e != &l.root; e = e.next {
+		if !l.expired(e, now) {
+			m.s = append(m.s, tuple[K, V]{key: e.key, val: e.val})
+		}
+	}
+	return m
+}
+
+// All iterates over a snapshot of the unexpired entries,
+// from the most recently used to the least recently used.
+func (l *LRU[K, V]) All() iter.Seq2[K, V] {
+	return l.Snapshot().All()
+}
+
+// Keys is like [LRU.All], but only for keys.
+func (l *LRU[K, V]) Keys() iter.Seq[K] {
+	return l.Snapshot().Keys()
+}
+
+// lookup returns the entry of key, or nil if it doesn't exist.
+// Expired entries are evicted and added to evicted.
+func (l *LRU[K, V]) lookup(key K, evicted *[]eviction[K, V]) *lruEntry[K, V] {
+	e, has := l.m[key]
+	if !has {
+		return nil
+	}
+	if l.expired(e, l.c.Now()) {
+		l.expire(e, evicted)
+		return nil
+	}
+	return e
+}
+
+func (l *LRU[K, V]) expired(e *lruEntry[K, V], now time.Time) bool {
+	return !e.expires.IsZero() && !now.Before(e.expires)
+}
+
+func (l *LRU[K, V]) expire(e *lruEntry[K, V], evicted *[]eviction[K, V]) {
+	l.remove(e)
+	l.stats.Expirations++
+	*evicted = append(*evicted, eviction[K, V]{e.key, e.val, EvictExpired})
+}
+
+func (l *LRU[K, V]) over() bool {
+	return len(l.m) > 0 &&
+		(l.c.MaxLen > 0 && len(l.m) > l.c.MaxLen ||
+			l.c.MaxCost > 0 && l.cost > l.c.MaxCost)
+}
+
+func (l *LRU[K, V]) remove(e *lruEntry[K, V]) {
+	l.unlink(e)
+	delete(l.m, e.key)
+	l.cost -= e.cost
+}
+
+func (l *LRU[K, V]) unlink(e *lruEntry[K, V]) {
+	e.prev.next = e.next
+	e.next.prev = e.prev
+	e.prev = nil
+	e.next = nil
+}
+
+func (l *LRU[K, V]) pushFront(e *lruEntry[K, V]) {
+	e.prev = &l.root
+	e.next = l.root.next
+	l.root.next.prev = e
+	l.root.next = e
+}
+
+// evicted calls the eviction callback for each of evicted.
+// It must be called without the lock held.
+func (l *LRU[K, V]) evicted(evicted []eviction[K, V]) {
+	if l.c.OnEvict == nil {
+		return
+	}
+	for _, e := range evicted {
+		l.c.OnEvict(e.key, e.val, e.reason)
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package omap_test
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/layer8co/toolbox/container/omap"
+)
+
+func TestLRU(t *testing.T) {
+
+	var evicted []string
+
+	l := omap.NewLRU(omap.LRUConfig[string, int]{
+		MaxLen: 3,
+		OnEvict: func(k string, v int, r omap.EvictReason) {
+			evicted = append(evicted, fmt.Sprintf("%s:%d:%v", k, v, r))
+		},
+	})
+
+	l.Set("a", 1)
+	l.Set("b", 2)
+	l.Set("c", 3)
+	l.Get("a")
+	l.Set("d", 4) // Evicts b.
+	l.Set("c", 30)
+	l.Set("e", 5) // Evicts a.
+
+	if _, has := l.Get("b"); has {
+		t.Error("b should have been evicted")
+	}
+	if v, has := l.Peek("d"); !has || v != 4 {
+		t.Errorf("Peek: want 4, true, got %v, %v", v, has)
+	}
+	if v, has := l.Delete("d"); !has || v != 4 {
+		t.Errorf("Delete: want 4, true, got %v, %v", v, has)
+	}
+
+	if diff := cmp.Diff([]string{"b:2:capacity", "a:1:capacity"}, evicted); diff != "" {
+		t.Errorf("incorrect evictions (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff("omap[e:5 c:30]", l.Snapshot().String()); diff != "" {
+		t.Errorf("incorrect entries (-want +got):\n%s", diff)
+	}
+
+	want := omap.LRUStats{Hits: 1, Misses: 1, Evictions: 2}
+	if diff := cmp.Diff(want, l.Stats()); diff != "" {
+		t.Errorf("incorrect stats (-want +got):\n%s", diff)
+	}
+}
+
+func TestLRUCost(t *testing.T) {
+
+	var evicted []string
+
+	l := omap.NewLRU(omap.LRUConfig[string, string]{
+		MaxCost: 10,
+		Cost: func(k, v string) int64 {
+			return int64(len(v))
+		},
+		OnEvict: func(k, v string, r omap.EvictReason) {
+			evicted = append(evicted, k)
+		},
+	})
+
+	l.Set("a", "xxxx")
+	l.Set("b", "xxxx")
+	l.Set("c", "xx")
+	if l.Cost() != 10 || l.Len() != 3 {
+		t.Errorf("want cost 10 and len 3, got %d and %d", l.Cost(), l.Len())
+	}
+
+	l.Set("d", "xxx") // Evicts a.
+	l.Set("b", "x")
+	if l.Cost() != 6 {
+		t.Errorf("want cost 6, got %d", l.Cost())
+	}
+
+	l.Set("big", "xxxxxxxxxxx") // Evicts everything, including itself.
+	if l.Cost() != 0 || l.Len() != 0 {
+		t.Errorf("want cost 0 and len 0, got %d and %d", l.Cost(), l.Len())
+	}
+
+	if diff := cmp.Diff([]string{"a", "c", "d", "b", "big"}, evicted); diff != "" {
+		t.Errorf("incorrect evictions (-want +got):\n%s", diff)
+	}
+}
+
+func TestLRUTTL(t *testing.T) {
+
+	now := time.Unix(0, 0)
+	var evicted []string
+
+	l := omap.NewLRU(omap.LRUConfig[string, int]{
+		TTL: time.Minute,
+		Now: func() time.Time { return now },
+		OnEvict: func(k string, v int, r omap.EvictReason) {
+			evicted = append(evicted, fmt.Sprintf("%s:%v", k, r))
+		},
+	})
+
+	l.Set("a", 1)
+	l.SetWithTTL("b", 2, time.Hour)
+	l.SetWithTTL("c", 3, 0)
+	l.Set("d", 4)
+
+	now = now.Add(time.Minute)
+
+	if _, has := l.Get("a"); has {
+		t.Error("a should have expired")
+	}
+	if diff := cmp.Diff([]string{"c", "b"}, slices.Collect(l.Keys())); diff != "" {
+		t.Errorf("incorrect keys (-want +got):\n%s", diff)
+	}
+
+	l.RemoveExpired()
+	if l.Len() != 2 {
+		t.Errorf("want len 2, got %d", l.Len())
+	}
+
+	now = now.Add(time.Hour)
+	if _, has := l.Peek("b"); has {
+		t.Error("b should have expired")
+	}
+	if v, has := l.Get("c"); !has || v != 3 {
+		t.Errorf("Get: want 3, true, got %v, %v", v, has)
+	}
+
+	if diff := cmp.Diff([]string{"a:expired", "d:expired", "b:expired"}, evicted); diff != "" {
+		t.Errorf("incorrect evictions (-want +got):\n%s", diff)
+	}
+	want := omap.LRUStats{Hits: 1, Misses: 1, Expirations: 3}
+	if diff := cmp.Diff(want, l.Stats()); diff != "" {
+		t.Errorf("incorrect stats (-want +got):\n%s", diff)
+	}
+}
+
+func TestLRUConcurrency(t *testing.T) {
+
+	const (
+		goroutines = 8
+		iterations = 1000
+	)
+
+	var mu sync.Mutex
+	evictions := 0
+
+	var l *omap.LRU[int, int]
+	l = omap.NewLRU(omap.LRUConfig[int, int]{
+		MaxLen: 100,
+		OnEvict: func(k, v int, r omap.EvictReason) {
+			// Calling back into the cache must not deadlock.
+			l.Peek(k)
+			mu.Lock()
+			evictions++
+			mu.Unlock()
+		},
+	})
+
+	var wg sync.WaitGroup
+	for g := range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range iterations {
+				l.Set(g*iterations+i, i)
+				l.Get(i)
+				for range l.All() {
+					break
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if l.Len() != 100 {
+		t.Errorf("want len 100, got %d", l.Len())
+	}
+	if evictions != goroutines*iterations-100 {
+		t.Errorf("want %d evictions, got %d", goroutines*iterations-100, evictions)
+	}
+}
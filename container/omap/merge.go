@@ -0,0 +1,320 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package omap
+
+import (
+	"reflect"
+	"slices"
+)
+
+// MergeStrategy is used by [Merge].
+// See its documentation for details.
+type MergeStrategy uint8
+
+const (
+	// MergeReplace sets the entries of src in dst,
+	// replacing existing values.
+	MergeReplace MergeStrategy = iota
+
+	// MergeDeep is like MergeReplace,
+	// except that when both values are of type Map[string, any],
+	// they're merged recursively.
+	MergeDeep
+
+	// MergeKeep is like MergeDeep,
+	// except that existing values in dst are kept,
+	// so only missing entries are added.
+	MergeKeep
+)
+
+// Merge merges the entries of src into dst according to strategy.
+// Entries that don't exist in dst are appended in the order of src.
+//
+// Values that are copied from src are deep copies
+// as far as Map[string, any] and []any values go,
+// so modifying dst later doesn't affect src.
+//
+// For example, layered configuration can be implemented like:
+//
+//	cfg := omap.New[string, any]()
+//	omap.Merge(&cfg, defaults, omap.MergeDeep)
+//	omap.Merge(&cfg, env, omap.MergeDeep)
+//	omap.Merge(&cfg, overrides, omap.MergeDeep)
+func Merge[K comparable, V any](dst *Map[K, V], src Map[K, V], strategy MergeStrategy) {
+
+	Init(dst)
+
+	for k, sv := range src.All() {
+
+		dv, has := dst.Get(k)
+
+		if !has {
+			dst.Set(k, cloneValue(sv))
+			continue
+		}
+
+		dm, dok := any(dv).(Map[string, any])
+		sm, sok := any(sv).(Map[string, any])
+
+		if dok && sok && strategy != MergeReplace && !dm.IsNil() {
+			Merge(&dm, sm, strategy)
+			continue
+		}
+
+		if strategy != MergeKeep {
+			dst.Set(k, cloneValue(sv))
+		}
+	}
+}
+
+// ChangeKind is used by [Change].
+// Changed and Moved can be combined.
+type ChangeKind uint8
+
+const (
+	Added ChangeKind = 1 << iota
+	Removed
+	Changed
+	Moved
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Changed:
+		return "changed"
+	case Moved:
+		return "moved"
+	case Changed | Moved:
+		return "changed+moved"
+	default:
+		return "invalid"
+	}
+}
+
+// Change is returned by [Diff].
+// See its documentation for details.
+type Change[K comparable, V any] struct {
+	Kind ChangeKind
+	Key  K
+
+	// Old and OldIndex are the value and position of the entry in a.
+	// They're unset for added entries.
+	Old      V
+	OldIndex int
+
+	// New and NewIndex are the value and position of the entry in b.
+	// They're unset for removed entries.
+	New      V
+	NewIndex int
+}
+
+// Diff returns the differences between a and b:
+// the removed entries in the order of a,
+// followed by the added, changed and moved entries in the order of b.
+//
+// Values are compared using eq, which defaults to a deep comparison
+// that respects the order of nested Map[string, any] values.
+//
+// The entries that are reported as moved are the ones
+// that don't belong to the longest sequence of keys
+// that a and b have in common in the same order.
+func Diff[K comparable, V any](a, b Map[K, V], eq func(x, y V) bool) []Change[K, V] {
+
+	if eq == nil {
+		eq = func(x, y V) bool {
+			return equalValue(x, y)
+		}
+	}
+
+	var changes []Change[K, V]
+
+	ai := map[K]int{}
+	for i, k := range slices.Collect(a.Keys()) {
+		ai[k] = i
+	}
+
+	bi := map[K]int{}
+	for i, k := range slices.Collect(b.Keys()) {
+		bi[k] = i
+	}
+
+	i := 0
+	for k, v := range a.All() {
+		if _, has := bi[k]; !has {
+			changes = append(changes, Change[K, V]{
+				Kind:     Removed,
+				Key:      k,
+				Old:      v,
+				OldIndex: i,
+			})
+		}
+		i++
+	}
+
+	// The keys that both maps have, in the order of a and b.
+	var ca, cb []K
+	for k := range a.Keys() {
+		if _, has := bi[k]; has {
+			ca = append(ca, k)
+		}
+	}
+	for k := range b.Keys() {
+		if _, has := ai[k]; has {
+			cb = append(cb, k)
+		}
+	}
+	stable := lcs(ca, cb)
+
+	i = 0
+	for k, v := range b.All() {
+
+		j, has := ai[k]
+
+		if !has {
+			changes = append(changes, Change[K, V]{
+				Kind:     Added,
+				Key:      k,
+				New:      v,
+				NewIndex: i,
+			})
+			i++
+			continue
+		}
+
+		old, _ := a.Get(k)
+
+		var kind ChangeKind
+		if !eq(old, v) {
+			kind |= Changed
+		}
+		if !stable[k] {
+			kind |= Moved
+		}
+
+		if kind != 0 {
+			changes = append(changes, Change[K, V]{
+				Kind:     kind,
+				Key:      k,
+				Old:      old,
+				OldIndex: j,
+				New:      v,
+				NewIndex: i,
+			})
+		}
+
+		i++
+	}
+
+	return changes
+}
+
+// lcs returns the set of elements that belong to
+// a longest common subsequence of a and b,
+// which must contain the same elements.
+func lcs[K comparable](a, b []K) map[K]bool {
+
+	n := len(a)
+	m := len(b)
+
+	// t[i][j] is the length of the LCS of a[i:] and b[j:].
+	t := make([][]int, n+1)
+	for i := range t {
+		t[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				t[i][j] = t[i+1][j+1] + 1
+			} else {
+				t[i][j] = max(t[i+1][j], t[i][j+1])
+			}
+		}
+	}
+
+	s := map[K]bool{}
+	for i, j := 0, 0; i < n && j < m; {
+		switch {
+		case a[i] == b[j]:
+			s[a[i]] = true
+			i++
+			j++
+		case t[i+1][j] >= t[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+
+	return s
+}
+
+// nestedMap is implemented by every Map instantiation,
+// so that nested maps can be cloned and compared
+// regardless of their key and value types.
+type nestedMap interface {
+	cloneAny() any
+	equalAny(other any) bool
+}
+
+// asNestedMap returns v as a nestedMap if it's a Map,
+// but not if it's a pointer to one.
+func asNestedMap(v any) (nestedMap, bool) {
+	m, ok := v.(nestedMap)
+	return m, ok && reflect.TypeOf(v).Kind() != reflect.Pointer
+}
+
+func (m Map[K, V]) cloneAny() any {
+	return m.Clone()
+}
+
+func (m Map[K, V]) equalAny(other any) bool {
+	o, ok := other.(Map[K, V])
+	return ok && Equal(m, o, nil)
+}
+
+// cloneValue returns a deep copy of v
+// as far as Map and []any values go.
+func cloneValue[V any](v V) V {
+	if m, ok := asNestedMap(v); ok {
+		return m.cloneAny().(V)
+	}
+	switch x := any(v).(type) {
+	case []any:
+		if x == nil {
+			return v
+		}
+		s := make([]any, len(x))
+		for i, e := range x {
+			s[i] = cloneValue(e)
+		}
+		return any(s).(V)
+	}
+	return v
+}
+
+// equalValue reports whether x and y are deeply equal,
+// comparing Map values in order.
+func equalValue(x, y any) bool {
+	if m, ok := asNestedMap(x); ok {
+		return m.equalAny(y)
+	}
+	switch x := x.(type) {
+	case []any:
+		y, ok := y.([]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equalValue(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(x, y)
+}
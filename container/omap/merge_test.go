@@ -0,0 +1,104 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package omap_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/layer8co/toolbox/container/omap"
+)
+
+func TestMerge(t *testing.T) {
+
+	defaults := `{"log": {"level": "info", "file": "a.log"}, "port": 80}`
+	env := `{"port": 8080, "log": {"level": "debug"}, "debug": true}`
+
+	tests := []struct {
+		strategy omap.MergeStrategy
+		want     string
+	}{
+		{
+			omap.MergeReplace,
+			"omap[log:omap[level:debug] port:8080 debug:true]",
+		},
+		{
+			omap.MergeDeep,
+			"omap[log:omap[level:debug file:a.log] port:8080 debug:true]",
+		},
+		{
+			omap.MergeKeep,
+			"omap[log:omap[level:info file:a.log] port:80 debug:true]",
+		},
+	}
+
+	for _, test := range tests {
+		var dst omap.Map[string, any]
+		omap.Merge(&dst, doc(t, defaults), test.strategy)
+		omap.Merge(&dst, doc(t, env), test.strategy)
+		if diff := cmp.Diff(test.want, dst.String()); diff != "" {
+			t.Errorf("strategy %d: incorrect result (-want +got):\n%s", test.strategy, diff)
+		}
+	}
+}
+
+func TestMergeCopies(t *testing.T) {
+
+	src := doc(t, `{"a": {"b": 1}, "s": [{"c": 2}]}`)
+
+	var dst omap.Map[string, any]
+	omap.Merge(&dst, src, omap.MergeDeep)
+	omap.Merge(&dst, doc(t, `{"a": {"b": 3}}`), omap.MergeDeep)
+	must(t, omap.SetPath(&dst, "s.0.c", 4))
+
+	if diff := cmp.Diff("omap[a:omap[b:1] s:[omap[c:2]]]", src.String()); diff != "" {
+		t.Errorf("src was modified (-want +got):\n%s", diff)
+	}
+}
+
+func TestDiff(t *testing.T) {
+
+	a := doc(t, `{"a": 1, "b": 2, "c": 3, "d": 4, "e": {"x": 1}}`)
+	b := doc(t, `{"b": 2, "a": 1, "c": 30, "e": {"x": 1}, "f": 6}`)
+
+	var got []string
+	for _, c := range omap.Diff(a, b, nil) {
+		got = append(got, fmt.Sprintf(
+			"%v %s %v@%d %v@%d",
+			c.Kind, c.Key, c.Old, c.OldIndex, c.New, c.NewIndex,
+		))
+	}
+
+	want := []string{
+		"removed d 4@3 <nil>@0",
+		"moved a 1@0 1@1",
+		"changed c 3@2 30@2",
+		"added f <nil>@0 6@4",
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("incorrect result (-want +got):\n%s", diff)
+	}
+
+	if c := omap.Diff(a, a, nil); len(c) != 0 {
+		t.Errorf("expected no changes, got %v", c)
+	}
+
+	eq := func(x, y any) bool { return true }
+	if c := omap.Diff(a, b, eq); len(c) != 3 {
+		t.Errorf("expected 3 changes with a custom eq, got %v", c)
+	}
+}
+
+func doc(t *testing.T, s string) omap.Map[string, any] {
+	t.Helper()
+	var m omap.Map[string, any]
+	err := m.DecodeJSON(strings.NewReader(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
@@ -6,23 +6,44 @@ package omap
 
 import (
 	"fmt"
+	"io"
 	"iter"
 	"slices"
 	"strings"
+
+	"go.yaml.in/yaml/v4"
 )
 
 // Map is an ordered map.
+//
+// Like builtin maps, Map is a reference type:
+// copies of a Map share the same entries,
+// so modifying one of them modifies all of them.
+// Use [Map.Clone] to get an independent copy.
+//
+// The zero value is an empty map ready to use,
+// which is only initialized when it's first modified.
+// Until then, copies of it are independent of each other.
+// [Init] can be used to initialize a map explicitly.
 type Map[K comparable, V any] struct {
 	*omap[K, V]
 }
 
 type omap[K comparable, V any] struct {
-	s []tuple[K, V]
+	s    []tuple[K, V]
+	yaml *yaml.Node // See [Map.UnmarshalYAML].
+}
+
+// Entry is an entry of a [Map].
+type Entry[K comparable, V any] struct {
+	Key   K
+	Value V
 }
 
 type tuple[K comparable, V any] struct {
-	key K
-	val V
+	key  K
+	val  V
+	yaml *yamlEntry // See [Map.UnmarshalYAML].
 }
 
 func New[K comparable, V any](size ...int) Map[K, V] {
@@ -57,6 +78,7 @@ func (m Map[K, V]) Get(key K) (val V, has bool) {
 }
 
 func (m *Map[K, V]) Set(key K, val V) {
+	m.init()
 	i := m.index(key)
 	if i == -1 {
 		m.s = append(m.s, tuple[K, V]{
@@ -76,8 +98,9 @@ func (m *Map[K, V]) Delete(key K) (val V, has bool) {
 	if i == -1 {
 		return val, false
 	}
+	val = m.s[i].val
 	m.s = slices.Delete(m.s, i, i+1)
-	return m.s[i].val, true
+	return val, true
 }
 
 func (m Map[K, V]) Len() int {
@@ -137,6 +160,80 @@ func (m Map[K, V]) Values() iter.Seq[V] {
 	}
 }
 
+// Clone returns a copy of the map that doesn't share its entries.
+//
+// Map values of any type and []any values are cloned recursively,
+// like the nested documents created by decoding into Map[string, any].
+// Other values are copied by assignment.
+// YAML comments and styles (see [Map.UnmarshalYAML]) are kept.
+func (m Map[K, V]) Clone() Map[K, V] {
+	if m.IsNil() {
+		return m
+	}
+	c := New[K, V](len(m.s))
+	c.yaml = m.yaml
+	for _, t := range m.s {
+		t.val = cloneValue(t.val)
+		c.s = append(c.s, t)
+	}
+	return c
+}
+
+// Clear deletes all of the entries of the map.
+func (m *Map[K, V]) Clear() {
+	if m.IsNil() {
+		return
+	}
+	clear(m.s)
+	m.s = m.s[:0]
+}
+
+// Equal reports whether a and b have the same entries in the same order,
+// comparing values using eq.
+// A nil map is equal to an empty map.
+//
+// If eq is nil, values are compared using [reflect.DeepEqual],
+// except for Map[string, any] values, which are compared using Equal.
+func Equal[K comparable, V any](a, b Map[K, V], eq func(x, y V) bool) bool {
+	if a.Len() != b.Len() {
+		return false
+	}
+	for i := range a.Len() {
+		x, y := a.s[i], b.s[i]
+		if x.key != y.key {
+			return false
+		}
+		if eq == nil && !equalValue(x.val, y.val) || eq != nil && !eq(x.val, y.val) {
+			return false
+		}
+	}
+	return true
+}
+
+// Entries returns the entries of the map in order.
+//
+// Unlike [Map.All], it can be used where iterators can't,
+// like ranging over the entries of a map in [text/template]:
+//
+//	{{range .Entries}}{{.Key}}: {{.Value}}{{end}}
+func (m Map[K, V]) Entries() []Entry[K, V] {
+	if m.IsNil() {
+		return nil
+	}
+	x := make([]Entry[K, V], len(m.s))
+	for i, t := range m.s {
+		x[i] = Entry[K, V]{t.key, t.val}
+	}
+	return x
+}
+
+// Equal is like [Equal] with a nil eq.
+// It allows go-cmp to compare maps, which it can't do otherwise
+// because of their unexported fields.
+func (m Map[K, V]) Equal(o Map[K, V]) bool {
+	return Equal(m, o, nil)
+}
+
 func (m Map[K, V]) String() string {
 	if m.IsNil() {
 		return "omap[]"
@@ -154,6 +251,44 @@ func (m Map[K, V]) String() string {
 	return sb.String()
 }
 
+// Format implements [fmt.Formatter].
+//
+// The %#v verb formats the map as a Go composite literal
+// with its entries in order, like:
+//
+//	omap.Map[string,int]{"a": 1, "b": 2}
+//
+// The v, s, q, x and X verbs format the result of [Map.String]
+// like they would a string, with the same flags, width and precision.
+// Other verbs are invalid, and are reported like fmt reports bad verbs:
+//
+//	%!d(omap.Map[string,int]=omap[a:1 b:2])
+func (m Map[K, V]) Format(f fmt.State, verb rune) {
+
+	if verb == 'v' && f.Flag('#') {
+		if m.IsNil() {
+			fmt.Fprintf(f, "%T(nil)", m)
+			return
+		}
+		fmt.Fprintf(f, "%T{", m)
+		for i, t := range m.s {
+			if i > 0 {
+				io.WriteString(f, ", ")
+			}
+			fmt.Fprintf(f, "%#v: %#v", t.key, t.val)
+		}
+		io.WriteString(f, "}")
+		return
+	}
+
+	switch verb {
+	case 'v', 's', 'q', 'x', 'X':
+		fmt.Fprintf(f, fmt.FormatString(f, verb), m.String())
+	default:
+		fmt.Fprintf(f, "%%!%c(%T=%s)", verb, m, m.String())
+	}
+}
+
 func (m *Map[K, V]) init() {
 	if m.omap == nil {
 		m.omap = new(omap[K, V])
@@ -0,0 +1,370 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package omap_test
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/layer8co/toolbox/container/omap"
+	"go.yaml.in/yaml/v4"
+)
+
+func TestZeroValue(t *testing.T) {
+
+	var m omap.Map[string, int]
+	if _, has := m.Get("a"); has || m.Len() != 0 {
+		t.Errorf("zero map is not empty: %v", m)
+	}
+	if _, has := m.Delete("a"); has {
+		t.Error("Delete on a zero map found a value")
+	}
+	m.Clear()
+
+	m.Set("a", 1)
+	if v, has := m.Get("a"); !has || v != 1 {
+		t.Errorf("Get: want 1, true, got %v, %v", v, has)
+	}
+}
+
+func TestDelete(t *testing.T) {
+
+	m := omap.New[string, int]()
+	m.Set("a", 1)
+	m.Set("b", 2)
+	m.Set("c", 3)
+
+	for _, k := range []string{"c", "a", "b"} {
+		v, has := m.Delete(k)
+		if !has || v != int(k[0]-'a'+1) {
+			t.Errorf("Delete(%q): want %d, true, got %v, %v", k, k[0]-'a'+1, v, has)
+		}
+	}
+	if m.Len() != 0 {
+		t.Errorf("want empty map, got %v", m)
+	}
+}
+
+func TestAliasing(t *testing.T) {
+
+	// Copies of a zero map are independent until it's initialized.
+	var a omap.Map[string, int]
+	b := a
+	b.Set("b", 1)
+	if a.Len() != 0 {
+		t.Errorf("copy of a zero map was modified: %v", a)
+	}
+
+	// After that, copies share their entries.
+	a.Set("a", 1)
+	c := a
+	c.Set("c", 2)
+	c.Delete("a")
+	if diff := cmp.Diff("omap[c:2]", a.String()); diff != "" {
+		t.Errorf("copy does not alias (-want +got):\n%s", diff)
+	}
+
+	// Except for clones.
+	d := a.Clone()
+	d.Set("d", 3)
+	d.Clear()
+	if diff := cmp.Diff("omap[c:2]", a.String()); diff != "" {
+		t.Errorf("clone aliases (-want +got):\n%s", diff)
+	}
+}
+
+func TestClone(t *testing.T) {
+
+	m := doc(t, `{"a": {"b": [1, {"c": 2}]}, "d": 3}`)
+	c := m.Clone()
+
+	must(t, omap.SetPath(&c, "a.b.1.c", 20))
+	must(t, omap.SetPath(&c, "a.x", 1))
+	omap.DeletePath(c, "d")
+
+	if diff := cmp.Diff("omap[a:omap[b:[1 omap[c:2]]] d:3]", m.String()); diff != "" {
+		t.Errorf("original was modified (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff("omap[a:omap[b:[1 omap[c:20]] x:1]]", c.String()); diff != "" {
+		t.Errorf("incorrect clone (-want +got):\n%s", diff)
+	}
+
+	var z omap.Map[int, int]
+	if !z.Clone().IsNil() {
+		t.Error("clone of a nil map is not nil")
+	}
+}
+
+func TestEqual(t *testing.T) {
+
+	tests := []struct {
+		line int
+		a    string
+		b    string
+		want bool
+	}{
+		{line(), `{}`, `{}`, true},
+		{line(), `{"a": 1, "b": {"c": [1]}}`, `{"a": 1, "b": {"c": [1]}}`, true},
+		{line(), `{"a": 1, "b": 2}`, `{"b": 2, "a": 1}`, false},
+		{line(), `{"a": {"x": 1, "y": 2}}`, `{"a": {"y": 2, "x": 1}}`, false},
+		{line(), `{"a": 1}`, `{"a": 2}`, false},
+		{line(), `{"a": 1}`, `{"a": 1, "b": 2}`, false},
+	}
+
+	for _, test := range tests {
+		if got := omap.Equal(doc(t, test.a), doc(t, test.b), nil); got != test.want {
+			t.Errorf("line %d: want %v, got %v", test.line, test.want, got)
+		}
+	}
+
+	var z omap.Map[string, any]
+	if !omap.Equal(z, omap.New[string, any](), nil) {
+		t.Error("nil map should equal an empty map")
+	}
+
+	eq := func(x, y any) bool { return true }
+	if !omap.Equal(doc(t, `{"a": 1}`), doc(t, `{"a": 2}`), eq) {
+		t.Error("custom eq was not used")
+	}
+}
+
+// TestModel applies random operations to a map and to a reference model
+// (a slice of keys and a builtin map) and checks that they agree.
+func TestModel(t *testing.T) {
+
+	type model struct {
+		keys []int
+		vals map[int]int
+	}
+
+	for seed := range uint64(100) {
+
+		r := rand.New(rand.NewPCG(seed, seed))
+
+		var m omap.Map[int, int]
+		want := model{vals: map[int]int{}}
+
+		// alias is a copy of m once it's initialized,
+		// which must always have the same entries.
+		var alias *omap.Map[int, int]
+
+		for i := range 200 {
+
+			k := r.IntN(16)
+			v := r.Int()
+
+			switch op := r.IntN(10); {
+
+			case op < 5:
+				m.Set(k, v)
+				if _, has := want.vals[k]; !has {
+					want.keys = append(want.keys, k)
+				}
+				want.vals[k] = v
+
+			case op < 8:
+				got, has := m.Delete(k)
+				w, wantHas := want.vals[k]
+				if got != w || has != wantHas {
+					t.Fatalf("seed %d, op %d: Delete(%d): want %v, %v, got %v, %v", seed, i, k, w, wantHas, got, has)
+				}
+				want.keys = slices.DeleteFunc(want.keys, func(x int) bool { return x == k })
+				delete(want.vals, k)
+
+			case op < 9:
+				c := m.Clone()
+				c.Set(-1, 0)
+				c.Clear()
+
+			default:
+				if r.IntN(4) == 0 {
+					m.Clear()
+					want.keys = nil
+					clear(want.vals)
+				}
+			}
+
+			if alias == nil && !m.IsNil() {
+				c := m
+				alias = &c
+			}
+
+			if diff := cmp.Diff(want.keys, slices.Collect(m.Keys()), cmpopts.EquateEmpty()); diff != "" {
+				t.Fatalf("seed %d, op %d: incorrect keys (-want +got):\n%s", seed, i, diff)
+			}
+			for _, k := range want.keys {
+				if v, has := m.Get(k); !has || v != want.vals[k] {
+					t.Fatalf("seed %d, op %d: Get(%d): want %v, true, got %v, %v", seed, i, k, want.vals[k], v, has)
+				}
+			}
+			if m.Len() != len(want.keys) {
+				t.Fatalf("seed %d, op %d: Len: want %d, got %d", seed, i, len(want.keys), m.Len())
+			}
+			if alias != nil && !omap.Equal(m, *alias, nil) {
+				t.Fatalf("seed %d, op %d: copy does not alias: %v != %v", seed, i, m, *alias)
+			}
+		}
+	}
+}
+
+func TestCloneYAML(t *testing.T) {
+
+	in := "# Head.\na: 1 # A.\nb: [x, y]\n"
+
+	var m omap.Map[string, any]
+	err := yaml.Unmarshal([]byte(in), &m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := yaml.Marshal(m.Clone())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(in, string(out)); diff != "" {
+		t.Errorf("comments were lost (-want +got):\n%s", diff)
+	}
+}
+
+func TestEntries(t *testing.T) {
+
+	m := omap.New[string, int]()
+	m.Set("b", 1)
+	m.Set("a", 2)
+
+	want := []omap.Entry[string, int]{{"b", 1}, {"a", 2}}
+	if diff := cmp.Diff(want, m.Entries()); diff != "" {
+		t.Errorf("incorrect entries (-want +got):\n%s", diff)
+	}
+
+	tmpl := template.Must(template.New("").Parse(
+		"{{range .Entries}}{{.Key}}={{.Value}};{{end}}",
+	))
+	var sb strings.Builder
+	err := tmpl.Execute(&sb, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff("b=1;a=2;", sb.String()); diff != "" {
+		t.Errorf("incorrect template output (-want +got):\n%s", diff)
+	}
+}
+
+func TestFormat(t *testing.T) {
+
+	m := omap.New[string, any]()
+	m.Set("b", 1.5)
+	m.Set("a", doc(t, `{"x": "y"}`))
+
+	var z omap.Map[int, bool]
+
+	tests := []struct {
+		line   int
+		format string
+		arg    any
+		want   string
+	}{
+		{line(), "%v", m, "omap[b:1.5 a:omap[x:y]]"},
+		{line(), "%s", m, "omap[b:1.5 a:omap[x:y]]"},
+		{line(), "%q", m, `"omap[b:1.5 a:omap[x:y]]"`},
+		{line(), "%-10v|", z, "omap[]    |"},
+		{line(), "%x", z, "6f6d61705b5d"},
+		{line(), "%d", m, "%!d(omap.Map[string,interface {}]=omap[b:1.5 a:omap[x:y]])"},
+		{line(), "%#v", m, `omap.Map[string,interface {}]{"b": 1.5, "a": omap.Map[string,interface {}]{"x": "y"}}`},
+		{line(), "%#v", z, "omap.Map[int,bool](nil)"},
+		{line(), "%v", z, "omap[]"},
+		{line(), "%s", m.String(), "omap[b:1.5 a:omap[x:y]]"},
+	}
+
+	for _, test := range tests {
+		if diff := cmp.Diff(test.want, fmt.Sprintf(test.format, test.arg)); diff != "" {
+			t.Errorf("line %d: incorrect result (-want +got):\n%s", test.line, diff)
+		}
+	}
+}
+
+func TestCmp(t *testing.T) {
+
+	type config struct {
+		Name string
+		Opts omap.Map[string, any]
+		Tags omap.Set[string]
+	}
+
+	a := config{"a", doc(t, `{"x": 1, "y": [2]}`), omap.NewSet("p", "q")}
+	b := config{"a", doc(t, `{"x": 1, "y": [2]}`), omap.NewSet("p", "q")}
+
+	if diff := cmp.Diff(a, b); diff != "" {
+		t.Errorf("equal configs differ:\n%s", diff)
+	}
+
+	b.Opts.Set("x", 2)
+	if cmp.Equal(a, b) {
+		t.Error("different maps are equal")
+	}
+
+	b = config{"a", doc(t, `{"y": [2], "x": 1}`), omap.NewSet("p", "q")}
+	if cmp.Equal(a, b) {
+		t.Error("maps with different orders are equal")
+	}
+
+	b = config{"a", a.Opts, omap.NewSet("q", "p")}
+	if cmp.Equal(a, b) {
+		t.Error("sets with different orders are equal")
+	}
+}
+
+func TestNestedTypedMaps(t *testing.T) {
+
+	var decoded omap.Map[string, omap.Map[string, int]]
+	err := yaml.Unmarshal([]byte("t: {x: 1}\n"), &decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inner := omap.New[string, int]()
+	inner.Set("x", 1)
+	built := omap.New[string, omap.Map[string, int]]()
+	built.Set("t", inner)
+
+	if !decoded.Equal(built) {
+		t.Error("decoded map doesn't equal an identical built map")
+	}
+	if diff := cmp.Diff(built, decoded); diff != "" {
+		t.Errorf("cmp: decoded map differs (-want +got):\n%s", diff)
+	}
+
+	reordered := omap.New[string, int]()
+	reordered.Set("y", 2)
+	reordered.Set("x", 1)
+	other := built.Clone()
+	other.Set("t", reordered)
+	inner.Set("y", 2)
+	if built.Equal(other) {
+		t.Error("maps with differently ordered nested maps are equal")
+	}
+
+	c := built.Clone()
+	v, _ := c.Get("t")
+	v.Set("x", 10)
+	if diff := cmp.Diff("omap[t:omap[x:1 y:2]]", built.String()); diff != "" {
+		t.Errorf("nested map of the original was modified (-want +got):\n%s", diff)
+	}
+
+	// Pointers to maps are copied by assignment.
+	p := omap.New[string, *omap.Map[string, int]]()
+	p.Set("t", &inner)
+	if v, _ := p.Clone().Get("t"); v != &inner {
+		t.Error("pointer to nested map was not copied by assignment")
+	}
+	if !p.Equal(p.Clone()) {
+		t.Error("map of pointers doesn't equal its clone")
+	}
+}
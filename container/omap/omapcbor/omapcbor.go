@@ -0,0 +1,318 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package omapcbor implements CBOR encoding and decoding of [omap.Map]
+// that preserves the order of keys.
+//
+// It lives in its own package so that the CBOR library
+// is only built by programs that import it.
+//
+// When decoding into a map whose value type is any,
+// nested CBOR maps whose keys are all text strings
+// are decoded into ordered omap.Map[string, any] values,
+// and arrays into []any.
+// Nested omap.Map values of any type are encoded and decoded in order.
+package omapcbor
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/layer8co/toolbox/container/omap"
+	"github.com/layer8co/toolbox/container/omap/internal/anymap"
+)
+
+const (
+	majorArray = 4
+	majorMap   = 5
+
+	indefinite = 31
+	breakByte  = 0xff
+)
+
+var ErrNotMap = errors.New("cbor data item is not a map")
+
+// Marshal returns the CBOR encoding of m.
+// A nil map is encoded as CBOR null.
+func Marshal[K comparable, V any](m omap.Map[K, V]) ([]byte, error) {
+	am, _ := anymap.Of(m)
+	return appendMap(nil, am)
+}
+
+// Encode writes the CBOR encoding of m to w.
+func Encode[K comparable, V any](w io.Writer, m omap.Map[K, V]) error {
+	b, err := Marshal(m)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// Unmarshal decodes the CBOR map b into m.
+// CBOR null leaves m unchanged.
+func Unmarshal[K comparable, V any](b []byte, m *omap.Map[K, V]) error {
+	am, _ := anymap.Of(m)
+	return unmarshalMap(b, am)
+}
+
+// Decode reads the next CBOR data item from r and decodes it into m.
+func Decode[K comparable, V any](r io.Reader, m *omap.Map[K, V]) error {
+	var raw cbor.RawMessage
+	err := cbor.NewDecoder(r).Decode(&raw)
+	if err != nil {
+		return err
+	}
+	return Unmarshal(raw, m)
+}
+
+func appendMap(b []byte, m anymap.Map) ([]byte, error) {
+
+	if m.IsNil() {
+		return append(b, 0xf6), nil
+	}
+
+	b = appendHead(b, majorMap, uint64(m.Len()))
+
+	for k, v := range m.All() {
+		var err error
+		b, err = appendValue(b, k)
+		if err != nil {
+			return nil, err
+		}
+		b, err = appendValue(b, v)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return b, nil
+}
+
+func appendValue(b []byte, v any) ([]byte, error) {
+	if m, ok := anymap.Of(v); ok {
+		return appendMap(b, m)
+	}
+	switch v := v.(type) {
+	case []any:
+		b = appendHead(b, majorArray, uint64(len(v)))
+		for _, e := range v {
+			var err error
+			b, err = appendValue(b, e)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	default:
+		x, err := cbor.Marshal(v)
+		return append(b, x...), err
+	}
+}
+
+func unmarshalMap(b []byte, m anymap.Map) error {
+
+	if len(b) == 1 && b[0] == 0xf6 {
+		return nil
+	}
+
+	m.Init()
+
+	return eachPair(b, func(key, val []byte) error {
+		return m.Set(
+			func(k any) error {
+				err := cbor.Unmarshal(key, k)
+				if err != nil {
+					return fmt.Errorf("could not decode key into %v: %w", reflect.TypeOf(k).Elem(), err)
+				}
+				return nil
+			},
+			func(v any) error {
+				return unmarshalValue(val, v)
+			},
+		)
+	})
+}
+
+// unmarshalValue decodes b into the value pointed to by v.
+func unmarshalValue(b []byte, v any) error {
+	if m, ok := anymap.Of(v); ok {
+		return unmarshalMap(b, m)
+	}
+	if p, ok := v.(*any); ok {
+		var err error
+		*p, err = decodeAny(b)
+		return err
+	}
+	return cbor.Unmarshal(b, v)
+}
+
+// appendHead appends the head of a data item
+// of the given major type and argument to b.
+func appendHead(b []byte, major byte, n uint64) []byte {
+	m := major << 5
+	switch {
+	case n < 24:
+		return append(b, m|byte(n))
+	case n <= 0xff:
+		return append(b, m|24, byte(n))
+	case n <= 0xffff:
+		return binary.BigEndian.AppendUint16(append(b, m|25), uint16(n))
+	case n <= 0xffffffff:
+		return binary.BigEndian.AppendUint32(append(b, m|26), uint32(n))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, m|27), n)
+	}
+}
+
+// readHead returns the argument of the head of the data item b
+// if it's of the given major type, and the rest of b.
+// The argument of indefinite-length items is -1.
+func readHead(b []byte, major byte) (n int64, rest []byte, err error) {
+
+	if len(b) == 0 {
+		return 0, nil, io.ErrUnexpectedEOF
+	}
+	if b[0]>>5 != major {
+		return 0, nil, fmt.Errorf("unexpected cbor major type %d", b[0]>>5)
+	}
+
+	info := b[0] & 0x1f
+	b = b[1:]
+
+	var size int
+	switch {
+	case info < 24:
+		return int64(info), b, nil
+	case info == indefinite:
+		return -1, b, nil
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	default:
+		return 0, nil, errors.New("malformed cbor head")
+	}
+
+	if len(b) < size {
+		return 0, nil, io.ErrUnexpectedEOF
+	}
+
+	var u uint64
+	for _, c := range b[:size] {
+		u = u<<8 | uint64(c)
+	}
+	if int64(u) < 0 || u > uint64(len(b)) {
+		return 0, nil, fmt.Errorf("cbor length %d exceeds data", u)
+	}
+
+	return int64(u), b[size:], nil
+}
+
+// eachPair calls fn with the encoded key and value of each pair
+// of the CBOR map b, in order.
+func eachPair(b []byte, fn func(key, val []byte) error) error {
+
+	if len(b) > 0 && b[0]>>5 != majorMap {
+		return ErrNotMap
+	}
+
+	n, b, err := readHead(b, majorMap)
+	if err != nil {
+		return err
+	}
+
+	for i := int64(0); n < 0 || i < n; i++ {
+
+		if n < 0 {
+			if len(b) == 0 {
+				return io.ErrUnexpectedEOF
+			}
+			if b[0] == breakByte {
+				break
+			}
+		}
+
+		var key, val cbor.RawMessage
+
+		b, err = cbor.UnmarshalFirst(b, &key)
+		if err != nil {
+			return err
+		}
+		b, err = cbor.UnmarshalFirst(b, &val)
+		if err != nil {
+			return err
+		}
+
+		err = fn(key, val)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// decodeAny decodes the data item b,
+// decoding maps with text string keys into ordered maps.
+func decodeAny(b []byte) (any, error) {
+
+	if len(b) > 0 {
+		switch b[0] >> 5 {
+
+		case majorMap:
+			m := omap.New[string, any]()
+			err := eachPair(b, func(key, val []byte) error {
+				var k string
+				err := cbor.Unmarshal(key, &k)
+				if err != nil {
+					return err
+				}
+				v, err := decodeAny(val)
+				if err != nil {
+					return err
+				}
+				m.Set(k, v)
+				return nil
+			})
+			if err == nil {
+				return m, nil
+			}
+
+		case majorArray:
+			n, rest, err := readHead(b, majorArray)
+			if err != nil {
+				return nil, err
+			}
+			s := []any{}
+			for i := int64(0); n < 0 || i < n; i++ {
+				if n < 0 && len(rest) > 0 && rest[0] == breakByte {
+					break
+				}
+				var e cbor.RawMessage
+				rest, err = cbor.UnmarshalFirst(rest, &e)
+				if err != nil {
+					return nil, err
+				}
+				v, err := decodeAny(e)
+				if err != nil {
+					return nil, err
+				}
+				s = append(s, v)
+			}
+			return s, nil
+		}
+	}
+
+	var v any
+	err := cbor.Unmarshal(b, &v)
+	return v, err
+}
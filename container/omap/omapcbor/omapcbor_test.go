@@ -0,0 +1,130 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package omapcbor_test
+
+import (
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/google/go-cmp/cmp"
+	"github.com/layer8co/toolbox/container/omap"
+	"github.com/layer8co/toolbox/container/omap/omapcbor"
+)
+
+func TestRoundTrip(t *testing.T) {
+
+	inner := omap.New[string, any]()
+	inner.Set("y", 1)
+	inner.Set("x", []any{"b", "a"})
+
+	m := omap.New[string, any]()
+	m.Set("z", uint64(1))
+	m.Set("a", inner)
+	m.Set("m", []any{inner})
+
+	b, err := omapcbor.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got omap.Map[string, any]
+	if err := omapcbor.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(m.String(), got.String()); diff != "" {
+		t.Errorf("incorrect round-trip (-want +got):\n%s", diff)
+	}
+}
+
+func TestTypedKeys(t *testing.T) {
+
+	m := omap.New[int, string]()
+	for _, k := range []int{300, -1, 7} {
+		m.Set(k, "v")
+	}
+
+	b, err := omapcbor.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Also check that the encoding is readable by plain CBOR decoders.
+	var plain map[int]string
+	if err := cbor.Unmarshal(b, &plain); err != nil || len(plain) != 3 {
+		t.Fatalf("plain decoding failed: %v, %v", plain, err)
+	}
+
+	var got omap.Map[int, string]
+	if err := omapcbor.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(m.String(), got.String()); diff != "" {
+		t.Errorf("incorrect round-trip (-want +got):\n%s", diff)
+	}
+}
+
+func TestNestedTypedMaps(t *testing.T) {
+
+	inner := omap.New[string, int]()
+	inner.Set("z", 1)
+	inner.Set("a", 2)
+
+	m := omap.New[string, omap.Map[string, int]]()
+	m.Set("t", inner)
+	m.Set("n", omap.Map[string, int]{})
+
+	b, err := omapcbor.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got omap.Map[string, omap.Map[string, int]]
+	if err := omapcbor.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(m.String(), got.String()); diff != "" {
+		t.Errorf("incorrect round-trip (-want +got):\n%s", diff)
+	}
+
+	// Nested typed maps in values of type any are encoded in order too.
+	var untyped omap.Map[string, any]
+	untyped.Set("t", inner)
+	b, err = omapcbor.Marshal(untyped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gotUntyped omap.Map[string, any]
+	if err := omapcbor.Unmarshal(b, &gotUntyped); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff("omap[t:omap[z:1 a:2]]", gotUntyped.String()); diff != "" {
+		t.Errorf("incorrect round-trip (-want +got):\n%s", diff)
+	}
+}
+
+func TestIndefiniteLength(t *testing.T) {
+
+	// {_ "b": 1, "a": [_ 2]}
+	b := []byte{0xbf, 0x61, 'b', 0x01, 0x61, 'a', 0x9f, 0x02, 0xff, 0xff}
+
+	var m omap.Map[string, any]
+	if err := omapcbor.Unmarshal(b, &m); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "omap[b:1 a:[2]]"
+	if diff := cmp.Diff(want, m.String()); diff != "" {
+		t.Errorf("incorrect decoding (-want +got):\n%s", diff)
+	}
+}
+
+func TestNotMap(t *testing.T) {
+	var m omap.Map[string, any]
+	if err := omapcbor.Unmarshal([]byte{0x01}, &m); err != omapcbor.ErrNotMap {
+		t.Errorf("incorrect error: want ErrNotMap, got %v", err)
+	}
+}
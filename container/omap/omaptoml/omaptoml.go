@@ -0,0 +1,321 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package omaptoml implements TOML encoding and decoding of [omap.Map]
+// that preserves the order of keys.
+//
+// It lives in its own package so that the TOML library
+// is only built by programs that import it.
+//
+// TOML requires the key/value pairs of a table to precede its subtables,
+// so when encoding, entries whose values are tables
+// are moved after the rest, otherwise keeping their order.
+//
+// When decoding into a map whose value type is any,
+// nested tables are decoded into ordered omap.Map[string, any] values,
+// and arrays into []any.
+// Nested omap.Map values of any type with string keys
+// are encoded and decoded as tables in order.
+package omaptoml
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/layer8co/toolbox/container/omap"
+	"github.com/layer8co/toolbox/container/omap/internal/anymap"
+)
+
+// Marshal returns the TOML encoding of m.
+func Marshal[K ~string, V any](m omap.Map[K, V]) ([]byte, error) {
+	b := new(bytes.Buffer)
+	err := Encode(b, m)
+	if err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// Encode writes the TOML encoding of m to w.
+func Encode[K ~string, V any](w io.Writer, m omap.Map[K, V]) error {
+	b := new(bytes.Buffer)
+	am, _ := anymap.Of(m)
+	err := encodeTable(b, nil, am)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(bytes.TrimLeft(b.Bytes(), "\n"))
+	return err
+}
+
+// Unmarshal decodes the TOML document b into m.
+func Unmarshal[K ~string, V any](b []byte, m *omap.Map[K, V]) error {
+	return Decode(bytes.NewReader(b), m)
+}
+
+// Decode decodes the TOML document read from r into m.
+func Decode[K ~string, V any](r io.Reader, m *omap.Map[K, V]) error {
+
+	var raw map[string]toml.Primitive
+
+	md, err := toml.NewDecoder(r).Decode(&raw)
+	if err != nil {
+		return err
+	}
+
+	d := &decoder{
+		md:    md,
+		order: keyOrder(md.Keys()),
+	}
+	am, _ := anymap.Of(m)
+	return d.decodeTable(nil, raw, am)
+}
+
+type decoder struct {
+	md    toml.MetaData
+	order map[string][]string
+}
+
+// decodeTable decodes the entries of the table at path into m.
+func (d *decoder) decodeTable(path toml.Key, raw map[string]toml.Primitive, m anymap.Map) error {
+
+	m.Init()
+
+	for _, key := range tableKeys(d.order, path, raw) {
+
+		p := append(slices.Clip(path), key)
+
+		err := m.Set(
+			func(k any) error {
+				v := reflect.ValueOf(k).Elem()
+				if v.Kind() != reflect.String {
+					return fmt.Errorf("map key type %v is not a string type", v.Type())
+				}
+				v.SetString(key)
+				return nil
+			},
+			func(v any) error {
+				return d.decodeValue(p, raw[key], v)
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("could not decode key %q: %w", p, err)
+		}
+	}
+
+	return nil
+}
+
+// decodeValue decodes the value at path into the value pointed to by v.
+func (d *decoder) decodeValue(path toml.Key, prim toml.Primitive, v any) error {
+
+	if m, ok := anymap.Of(v); ok {
+		var raw map[string]toml.Primitive
+		err := d.md.PrimitiveDecode(prim, &raw)
+		if err != nil {
+			return err
+		}
+		return d.decodeTable(path, raw, m)
+	}
+
+	if p, ok := v.(*any); ok {
+		err := d.md.PrimitiveDecode(prim, p)
+		*p = ordered(d.order, path, *p)
+		return err
+	}
+
+	return d.md.PrimitiveDecode(prim, v)
+}
+
+// encodeTable writes the entries of the table m at path to b.
+// The header of the table must already have been written.
+func encodeTable(b *bytes.Buffer, path toml.Key, m anymap.Map) error {
+
+	type entry struct {
+		key string
+		val any
+	}
+
+	var tables []entry
+
+	for k, v := range m.All() {
+
+		key, err := keyString(k)
+		if err != nil {
+			return err
+		}
+
+		if isTable(v) {
+			tables = append(tables, entry{key, v})
+			continue
+		}
+
+		s, err := encodeValue(nil, key, v)
+		if err != nil {
+			return err
+		}
+
+		// Structs, maps and arrays of them are encoded as tables.
+		if strings.HasPrefix(s, "[") {
+			tables = append(tables, entry{key, v})
+			continue
+		}
+
+		b.WriteString(s)
+	}
+
+	for _, t := range tables {
+
+		p := append(slices.Clip(path), t.key)
+
+		if m, ok := anymap.Of(t.val); ok {
+			fmt.Fprintf(b, "\n[%s]\n", p)
+			err := encodeTable(b, p, m)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		switch v := t.val.(type) {
+
+		case []any:
+			for _, e := range v {
+				fmt.Fprintf(b, "\n[[%s]]\n", p)
+				m, _ := anymap.Of(e)
+				err := encodeTable(b, p, m)
+				if err != nil {
+					return err
+				}
+			}
+
+		default:
+			s, err := encodeValue(path, t.key, t.val)
+			if err != nil {
+				return err
+			}
+			b.WriteByte('\n')
+			b.WriteString(s)
+		}
+	}
+
+	return nil
+}
+
+// keyString returns the key of an ordered map as a TOML key.
+func keyString(k any) (string, error) {
+	v := reflect.ValueOf(k)
+	if v.Kind() != reflect.String {
+		return "", fmt.Errorf("map key type %v is not a string type", v.Type())
+	}
+	return v.String(), nil
+}
+
+// isTable reports whether v is an ordered map
+// or a non-empty array of ordered maps.
+func isTable(v any) bool {
+	if _, ok := anymap.Of(v); ok {
+		return true
+	}
+	if v, ok := v.([]any); ok {
+		for _, e := range v {
+			if _, ok := anymap.Of(e); !ok {
+				return false
+			}
+		}
+		return len(v) > 0
+	}
+	return false
+}
+
+// encodeValue returns the TOML encoding of the entry key = val
+// in the table at path, without the header of the table.
+func encodeValue(path toml.Key, key string, val any) (string, error) {
+
+	var doc any = map[string]any{key: val}
+	for i := len(path) - 1; i >= 0; i-- {
+		doc = map[string]any{path[i]: doc}
+	}
+
+	b := new(strings.Builder)
+	enc := toml.NewEncoder(b)
+	enc.Indent = ""
+
+	err := enc.Encode(doc)
+	if err != nil {
+		return "", err
+	}
+
+	// The encoder writes the headers of the enclosing tables first.
+	s := b.String()
+	for range path {
+		_, s, _ = strings.Cut(s, "\n")
+	}
+
+	return strings.TrimLeft(s, "\n"), nil
+}
+
+// tableKeys returns the keys of the table at path in the order they were defined,
+// followed by any others in sorted order.
+func tableKeys[V any](order map[string][]string, path toml.Key, table map[string]V) []string {
+	keys := slices.Clip(order[path.String()])
+	for _, k := range slices.Sorted(maps.Keys(table)) {
+		if !slices.Contains(keys, k) {
+			keys = append(keys, k)
+		}
+	}
+	return slices.DeleteFunc(keys, func(k string) bool {
+		_, ok := table[k]
+		return !ok
+	})
+}
+
+// keyOrder returns the keys of each table in the order they were defined,
+// indexed by the path of the table joined with ".".
+func keyOrder(keys []toml.Key) map[string][]string {
+	order := map[string][]string{}
+	seen := map[string]bool{}
+	for _, k := range keys {
+		for i := range k {
+			full := k[:i+1].String()
+			if seen[full] {
+				continue
+			}
+			seen[full] = true
+			parent := k[:i].String()
+			order[parent] = append(order[parent], k[i])
+		}
+	}
+	return order
+}
+
+// ordered converts the tables in v, which is at path,
+// into ordered maps.
+func ordered(order map[string][]string, path toml.Key, v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		m := omap.New[string, any](len(v))
+		for _, k := range tableKeys(order, path, v) {
+			m.Set(k, ordered(order, append(slices.Clip(path), k), v[k]))
+		}
+		return m
+	case []map[string]any:
+		s := make([]any, len(v))
+		for i, e := range v {
+			s[i] = ordered(order, path, e)
+		}
+		return s
+	case []any:
+		for i, e := range v {
+			v[i] = ordered(order, path, e)
+		}
+		return v
+	}
+	return v
+}
@@ -0,0 +1,164 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package omaptoml_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/layer8co/toolbox/container/omap"
+	"github.com/layer8co/toolbox/container/omap/omaptoml"
+)
+
+func TestRoundTrip(t *testing.T) {
+
+	in := "" +
+		"z = 1\n" +
+		"a = \"x\"\n" +
+		"list = [3, 1, 2]\n" +
+		"inline = {y = 1, x = 2}\n" +
+		"\n" +
+		"[server]\n" +
+		"port = 80\n" +
+		"host = \"localhost\"\n" +
+		"\n" +
+		"[server.tls]\n" +
+		"key = \"k\"\n" +
+		"cert = \"c\"\n" +
+		"\n" +
+		"[[users]]\n" +
+		"name = \"b\"\n" +
+		"\n" +
+		"[[users]]\n" +
+		"name = \"a\"\n" +
+		"\n" +
+		"[\"quoted key\"]\n" +
+		"b = 1\n" +
+		"a = 2\n"
+
+	var m omap.Map[string, any]
+	if err := omaptoml.Unmarshal([]byte(in), &m); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "omap[z:1 a:x list:[3 1 2] inline:omap[y:1 x:2] " +
+		"server:omap[port:80 host:localhost tls:omap[key:k cert:c]] " +
+		"users:[omap[name:b] omap[name:a]] quoted key:omap[b:1 a:2]]"
+	if diff := cmp.Diff(want, m.String()); diff != "" {
+		t.Errorf("incorrect decoding (-want +got):\n%s", diff)
+	}
+
+	out, err := omaptoml.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantOut := "" +
+		"z = 1\n" +
+		"a = \"x\"\n" +
+		"list = [3, 1, 2]\n" +
+		"\n" +
+		"[inline]\n" +
+		"y = 1\n" +
+		"x = 2\n" +
+		"\n" +
+		"[server]\n" +
+		"port = 80\n" +
+		"host = \"localhost\"\n" +
+		"\n" +
+		"[server.tls]\n" +
+		"key = \"k\"\n" +
+		"cert = \"c\"\n" +
+		"\n" +
+		"[[users]]\n" +
+		"name = \"b\"\n" +
+		"\n" +
+		"[[users]]\n" +
+		"name = \"a\"\n" +
+		"\n" +
+		"[\"quoted key\"]\n" +
+		"b = 1\n" +
+		"a = 2\n"
+	if diff := cmp.Diff(wantOut, string(out)); diff != "" {
+		t.Errorf("incorrect encoding (-want +got):\n%s", diff)
+	}
+}
+
+func TestTypedValues(t *testing.T) {
+
+	type server struct {
+		Port int `toml:"port"`
+	}
+
+	in := "[b]\nport = 2\n\n[a]\nport = 1\n"
+
+	var m omap.Map[string, server]
+	if err := omaptoml.Unmarshal([]byte(in), &m); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "omap[b:{2} a:{1}]"
+	if diff := cmp.Diff(want, m.String()); diff != "" {
+		t.Errorf("incorrect decoding (-want +got):\n%s", diff)
+	}
+
+	out, err := omaptoml.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(in, string(out)); diff != "" {
+		t.Errorf("incorrect encoding (-want +got):\n%s", diff)
+	}
+}
+
+func TestNestedTypedMaps(t *testing.T) {
+
+	inner := omap.New[string, int]()
+	inner.Set("z", 1)
+	inner.Set("a", 2)
+
+	typed := omap.New[string, omap.Map[string, int]]()
+	typed.Set("t", inner)
+
+	out, err := omaptoml.Marshal(typed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "[t]\nz = 1\na = 2\n"
+	if diff := cmp.Diff(want, string(out)); diff != "" {
+		t.Errorf("incorrect encoding (-want +got):\n%s", diff)
+	}
+
+	var got omap.Map[string, omap.Map[string, int]]
+	if err := omaptoml.Unmarshal(out, &got); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(typed.String(), got.String()); diff != "" {
+		t.Errorf("incorrect decoding (-want +got):\n%s", diff)
+	}
+
+	// Nested typed maps in values of type any are tables too.
+	var untyped omap.Map[string, any]
+	untyped.Set("x", 1)
+	untyped.Set("t", inner)
+	out, err = omaptoml.Marshal(untyped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = "x = 1\n\n[t]\nz = 1\na = 2\n"
+	if diff := cmp.Diff(want, string(out)); diff != "" {
+		t.Errorf("incorrect encoding (-want +got):\n%s", diff)
+	}
+
+	// Keys that aren't strings can't be encoded.
+	badInner := omap.New[int, int]()
+	badInner.Set(1, 1)
+	bad := omap.New[string, omap.Map[int, int]]()
+	bad.Set("t", badInner)
+	if _, err := omaptoml.Marshal(bad); err == nil {
+		t.Error("expected error for non-string keys")
+	}
+}
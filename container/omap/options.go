@@ -0,0 +1,110 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package omap
+
+// EncodeOption configures [Map.EncodeJSON].
+type EncodeOption func(*encodeConfig)
+
+type encodeConfig struct {
+	prefix     string
+	indent     string
+	escapeHTML bool
+}
+
+// WithIndent is similar to [json.Encoder.SetIndent].
+func WithIndent(prefix, indent string) EncodeOption {
+	return func(c *encodeConfig) {
+		c.prefix = prefix
+		c.indent = indent
+	}
+}
+
+// WithEscapeHTML is similar to [json.Encoder.SetEscapeHTML].
+// HTML escaping is enabled by default.
+func WithEscapeHTML(on bool) EncodeOption {
+	return func(c *encodeConfig) {
+		c.escapeHTML = on
+	}
+}
+
+// DecodeOption configures [Map.DecodeJSON] and [Map.DecodeYAML].
+type DecodeOption func(*decodeConfig)
+
+type decodeConfig struct {
+	useNumber             bool
+	disallowUnknownFields bool
+	duplicateKeys         DuplicateKeys
+}
+
+// nested returns the config used for the nested maps
+// that are created when decoding values of type any.
+// Since these can't be multimaps,
+// [DuplicateKeysCollect] falls back to [DuplicateKeysLast] for them.
+func (c *decodeConfig) nested() *decodeConfig {
+	if c.duplicateKeys != DuplicateKeysCollect {
+		return c
+	}
+	n := *c
+	n.duplicateKeys = DuplicateKeysLast
+	return &n
+}
+
+// DuplicateKeys determines what decoding does
+// with keys that appear more than once in the same object.
+type DuplicateKeys uint8
+
+const (
+	// DuplicateKeysLast keeps the value of the last occurrence of the key,
+	// at the position of the first occurrence.
+	// This is the default.
+	DuplicateKeysLast DuplicateKeys = iota
+
+	// DuplicateKeysFirst keeps the value of the first occurrence of the key
+	// and ignores the rest.
+	DuplicateKeysFirst
+
+	// DuplicateKeysError fails the decoding with a [*DuplicateKeyError].
+	DuplicateKeysError
+
+	// DuplicateKeysCollect decodes the map as a multimap:
+	// the value type of the map must be a slice,
+	// and the value of each occurrence of a key
+	// is decoded as an element and appended to the slice of that key.
+	DuplicateKeysCollect
+)
+
+// WithDuplicateKeys sets how keys that appear more than once are handled.
+// See [DuplicateKeys].
+func WithDuplicateKeys(d DuplicateKeys) DecodeOption {
+	return func(c *decodeConfig) {
+		c.duplicateKeys = d
+	}
+}
+
+// WithStrict is a shorthand for
+// [WithDuplicateKeys]([DuplicateKeysError]) and [WithDisallowUnknownFields].
+func WithStrict() DecodeOption {
+	return func(c *decodeConfig) {
+		c.duplicateKeys = DuplicateKeysError
+		c.disallowUnknownFields = true
+	}
+}
+
+// WithUseNumber is similar to [json.Decoder.UseNumber].
+// It only affects JSON decoding.
+func WithUseNumber() DecodeOption {
+	return func(c *decodeConfig) {
+		c.useNumber = true
+	}
+}
+
+// WithDisallowUnknownFields causes decoding to fail
+// when a struct value contains a field that doesn't exist in the struct.
+// It's similar to [json.Decoder.DisallowUnknownFields]
+// and [yaml.WithKnownFields].
+func WithDisallowUnknownFields() DecodeOption {
+	return func(c *decodeConfig) {
+		c.disallowUnknownFields = true
+	}
+}
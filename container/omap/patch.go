@@ -0,0 +1,554 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package omap
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var ErrTestFailed = errors.New("test operation failed")
+
+// Patch is a JSON Patch document (RFC 6902).
+// See [ApplyPatch] and [CreatePatch].
+type Patch []PatchOperation
+
+// PatchOperation is an operation of a [Patch].
+// Path and From are JSON Pointers (RFC 6901).
+type PatchOperation struct {
+	Op    string
+	Path  string
+	From  string
+	Value any
+}
+
+func (o PatchOperation) MarshalJSON() ([]byte, error) {
+	m := New[string, any](4)
+	m.Set("op", o.Op)
+	if o.Op == "move" || o.Op == "copy" {
+		m.Set("from", o.From)
+	}
+	m.Set("path", o.Path)
+	if o.Op == "add" || o.Op == "replace" || o.Op == "test" {
+		m.Set("value", o.Value)
+	}
+	return m.MarshalJSON()
+}
+
+// UnmarshalJSON decodes objects in the value of o
+// into ordered Map[string, any] values.
+func (o *PatchOperation) UnmarshalJSON(b []byte) error {
+
+	var raw struct {
+		Op    string          `json:"op"`
+		Path  *string         `json:"path"`
+		From  *string         `json:"from"`
+		Value json.RawMessage `json:"value"`
+	}
+
+	err := json.Unmarshal(b, &raw)
+	if err != nil {
+		return err
+	}
+
+	switch raw.Op {
+	case "add", "replace", "test":
+		if raw.Value == nil {
+			return fmt.Errorf("%s operation is missing value", raw.Op)
+		}
+	case "move", "copy":
+		if raw.From == nil {
+			return fmt.Errorf("%s operation is missing from", raw.Op)
+		}
+	case "remove":
+	default:
+		return fmt.Errorf("invalid patch operation %q", raw.Op)
+	}
+	if raw.Path == nil {
+		return fmt.Errorf("%s operation is missing path", raw.Op)
+	}
+
+	*o = PatchOperation{
+		Op:   raw.Op,
+		Path: *raw.Path,
+	}
+	if raw.From != nil {
+		o.From = *raw.From
+	}
+	if raw.Value != nil {
+		dec := json.NewDecoder(bytes.NewReader(raw.Value))
+		return decodeJSONAny(dec, &decodeConfig{}, &o.Value)
+	}
+
+	return nil
+}
+
+// ApplyPatch applies patch to doc.
+// Either all of the operations are applied, or none are.
+//
+// Since doc is ordered, adding a member to an object appends it,
+// and replacing one keeps its position.
+// Moving a member to its own location therefore moves it to the end.
+//
+// The test operation compares objects regardless of their order,
+// and numbers regardless of their types.
+func ApplyPatch(doc *Map[string, any], patch Patch) error {
+
+	Init(doc)
+
+	p := patcher{root: cloneValue(*doc)}
+
+	for i, o := range patch {
+		err := p.apply(o)
+		if err != nil {
+			return fmt.Errorf("patch operation %d (%s %q): %w", i, o.Op, o.Path, err)
+		}
+	}
+
+	root, ok := p.root.(Map[string, any])
+	if !ok || root.IsNil() {
+		return fmt.Errorf("patched document is a %T, not an object", p.root)
+	}
+
+	*doc.omap = *root.omap
+	return nil
+}
+
+type patcher struct {
+	root any
+}
+
+func (p *patcher) apply(o PatchOperation) error {
+
+	path, err := parsePointer(o.Path)
+	if err != nil {
+		return err
+	}
+
+	switch o.Op {
+
+	case "add":
+		return p.add(path, cloneValue(o.Value))
+
+	case "remove":
+		_, err := p.remove(path)
+		return err
+
+	case "replace":
+		_, err := p.get(path)
+		if err != nil {
+			return err
+		}
+		return p.set(path, cloneValue(o.Value))
+
+	case "move", "copy":
+		from, err := parsePointer(o.From)
+		if err != nil {
+			return err
+		}
+		var v any
+		if o.Op == "move" {
+			if len(from) < len(path) && slices.Equal(from, path[:len(from)]) {
+				return fmt.Errorf("can not move %q into itself", o.From)
+			}
+			v, err = p.remove(from)
+		} else {
+			v, err = p.get(from)
+			v = cloneValue(v)
+		}
+		if err != nil {
+			return err
+		}
+		return p.add(path, v)
+
+	case "test":
+		v, err := p.get(path)
+		if err != nil {
+			return err
+		}
+		if !equalJSON(v, o.Value) {
+			return ErrTestFailed
+		}
+		return nil
+	}
+
+	return fmt.Errorf("invalid patch operation %q", o.Op)
+}
+
+// get returns the value at path.
+func (p *patcher) get(path []string) (any, error) {
+	cur := p.root
+	for i, key := range path {
+		var err error
+		cur, err = pointerChild(cur, key)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", formatPointer(path[:i+1]), err)
+		}
+	}
+	return cur, nil
+}
+
+// set sets the existing value at path to v.
+func (p *patcher) set(path []string, v any) error {
+
+	if len(path) == 0 {
+		p.root = v
+		return nil
+	}
+
+	parent, err := p.get(path[:len(path)-1])
+	if err != nil {
+		return err
+	}
+
+	key := path[len(path)-1]
+
+	switch parent := parent.(type) {
+	case Map[string, any]:
+		parent.Set(key, v)
+	case []any:
+		i, err := pointerIndex(parent, key, false)
+		if err != nil {
+			return err
+		}
+		parent[i] = v
+	}
+
+	return nil
+}
+
+func (p *patcher) add(path []string, v any) error {
+
+	if len(path) == 0 {
+		p.root = v
+		return nil
+	}
+
+	parent, err := p.get(path[:len(path)-1])
+	if err != nil {
+		return err
+	}
+
+	key := path[len(path)-1]
+
+	switch s := parent.(type) {
+	case Map[string, any]:
+		s.Set(key, v)
+		return nil
+	case []any:
+		i, err := pointerIndex(s, key, true)
+		if err != nil {
+			return err
+		}
+		return p.set(path[:len(path)-1], slices.Insert(s, i, v))
+	}
+
+	return fmt.Errorf("%q is a %T, not an object or array", formatPointer(path[:len(path)-1]), parent)
+}
+
+func (p *patcher) remove(path []string) (any, error) {
+
+	if len(path) == 0 {
+		return nil, errors.New("can not remove the document root")
+	}
+
+	v, err := p.get(path)
+	if err != nil {
+		return nil, err
+	}
+
+	parent, _ := p.get(path[:len(path)-1])
+	key := path[len(path)-1]
+
+	switch s := parent.(type) {
+	case Map[string, any]:
+		s.Delete(key)
+		return v, nil
+	case []any:
+		i, _ := pointerIndex(s, key, false)
+		return v, p.set(path[:len(path)-1], slices.Delete(s, i, i+1))
+	}
+
+	return v, nil
+}
+
+func pointerChild(v any, key string) (any, error) {
+	switch v := v.(type) {
+	case Map[string, any]:
+		e, has := v.Get(key)
+		if !has {
+			return nil, errors.New("does not exist")
+		}
+		return e, nil
+	case []any:
+		i, err := pointerIndex(v, key, false)
+		if err != nil {
+			return nil, err
+		}
+		return v[i], nil
+	}
+	return nil, fmt.Errorf("parent is a %T, not an object or array", v)
+}
+
+// pointerIndex parses key as an index into s.
+// If insert is true, the index can be len(s), written as "-".
+func pointerIndex(s []any, key string, insert bool) (int, error) {
+
+	n := len(s)
+	if insert {
+		n++
+		if key == "-" {
+			return len(s), nil
+		}
+	}
+
+	// Leading zeros and signs are not allowed.
+	if key == "" || (key[0] == '0' && key != "0") || key[0] == '+' || key[0] == '-' {
+		return 0, fmt.Errorf("invalid array index %q", key)
+	}
+
+	i, err := strconv.Atoi(key)
+	if err != nil {
+		return 0, fmt.Errorf("invalid array index %q", key)
+	}
+	if i >= n {
+		return 0, fmt.Errorf("array index %d out of range [0:%d]", i, n)
+	}
+
+	return i, nil
+}
+
+// parsePointer parses the JSON Pointer p into its reference tokens.
+func parsePointer(p string) ([]string, error) {
+
+	if p == "" {
+		return nil, nil
+	}
+	if p[0] != '/' {
+		return nil, fmt.Errorf("invalid json pointer %q", p)
+	}
+
+	tokens := strings.Split(p[1:], "/")
+	for i, t := range tokens {
+		for j := 0; j < len(t); j++ {
+			if t[j] == '~' && (j+1 == len(t) || (t[j+1] != '0' && t[j+1] != '1')) {
+				return nil, fmt.Errorf("invalid escape in json pointer %q", p)
+			}
+		}
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(t)
+	}
+
+	return tokens, nil
+}
+
+func formatPointer(tokens []string) string {
+	r := strings.NewReplacer("~", "~0", "/", "~1")
+	var sb strings.Builder
+	for _, t := range tokens {
+		sb.WriteByte('/')
+		sb.WriteString(r.Replace(t))
+	}
+	return sb.String()
+}
+
+// equalJSON reports whether x and y are equal
+// according to the test operation of JSON Patch.
+func equalJSON(x, y any) bool {
+
+	switch x := x.(type) {
+
+	case Map[string, any]:
+		y, ok := y.(Map[string, any])
+		if !ok || x.Len() != y.Len() {
+			return false
+		}
+		for k, v := range x.All() {
+			w, has := y.Get(k)
+			if !has || !equalJSON(v, w) {
+				return false
+			}
+		}
+		return true
+
+	case []any:
+		y, ok := y.([]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equalJSON(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if a, ok := number(x); ok {
+		b, ok := number(y)
+		return ok && a == b
+	}
+
+	return reflect.DeepEqual(x, y)
+}
+
+func number(v any) (float64, bool) {
+	if n, ok := v.(json.Number); ok {
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	r := reflect.ValueOf(v)
+	switch {
+	case r.CanInt():
+		return float64(r.Int()), true
+	case r.CanUint():
+		return float64(r.Uint()), true
+	case r.CanFloat():
+		return r.Float(), true
+	}
+	return 0, false
+}
+
+// CreatePatch returns a patch that turns a into b
+// when applied using [ApplyPatch], including the order of keys.
+//
+// Nested Map[string, any] values are compared recursively,
+// other values that differ are replaced.
+func CreatePatch(a, b Map[string, any]) Patch {
+	var patch Patch
+	createPatch(&patch, nil, a, b)
+	return patch
+}
+
+func createPatch(patch *Patch, path []string, a, b Map[string, any]) {
+
+	at := func(key string) string {
+		return formatPointer(append(slices.Clip(path), key))
+	}
+
+	for k := range a.Keys() {
+		if _, has := b.Get(k); !has {
+			*patch = append(*patch, PatchOperation{Op: "remove", Path: at(k)})
+		}
+	}
+
+	// The keys of a that remain, in order.
+	var cur []string
+
+	for k, av := range a.All() {
+
+		bv, has := b.Get(k)
+		if !has {
+			continue
+		}
+		cur = append(cur, k)
+
+		am, aok := av.(Map[string, any])
+		bm, bok := bv.(Map[string, any])
+
+		switch {
+		case aok && bok && !am.IsNil() && !bm.IsNil():
+			createPatch(patch, append(slices.Clip(path), k), am, bm)
+		case !equalValue(av, bv):
+			*patch = append(*patch, PatchOperation{
+				Op:    "replace",
+				Path:  at(k),
+				Value: cloneValue(bv),
+			})
+		}
+	}
+
+	// Since added and moved keys go to the end,
+	// the longest prefix of b that is in order in cur stays in place,
+	// and the rest of b is added or moved to the end in order.
+	keys := slices.Collect(b.Keys())
+	i, j := 0, 0
+	for ; i < len(keys); i++ {
+		for j < len(cur) && cur[j] != keys[i] {
+			j++
+		}
+		if j == len(cur) {
+			break
+		}
+		j++
+	}
+
+	for _, k := range keys[i:] {
+		if _, has := a.Get(k); has {
+			*patch = append(*patch, PatchOperation{Op: "move", From: at(k), Path: at(k)})
+		} else {
+			v, _ := b.Get(k)
+			*patch = append(*patch, PatchOperation{Op: "add", Path: at(k), Value: cloneValue(v)})
+		}
+	}
+}
+
+// ApplyMergePatch applies the JSON Merge Patch (RFC 7386) patch to doc.
+// New keys are appended in the order of patch.
+func ApplyMergePatch(doc *Map[string, any], patch Map[string, any]) {
+
+	Init(doc)
+
+	for k, pv := range patch.All() {
+
+		if pv == nil {
+			doc.Delete(k)
+			continue
+		}
+
+		pm, ok := pv.(Map[string, any])
+		if !ok {
+			doc.Set(k, cloneValue(pv))
+			continue
+		}
+
+		dv, _ := doc.Get(k)
+		dm, ok := dv.(Map[string, any])
+		if !ok || dm.IsNil() {
+			dm = New[string, any]()
+		}
+		ApplyMergePatch(&dm, pm)
+		doc.Set(k, dm)
+	}
+}
+
+// CreateMergePatch returns a merge patch that turns a into b
+// when applied using [ApplyMergePatch].
+//
+// Merge patches can't express reordering of existing keys
+// nor setting values to null.
+func CreateMergePatch(a, b Map[string, any]) Map[string, any] {
+
+	patch := New[string, any]()
+
+	for k := range a.Keys() {
+		if _, has := b.Get(k); !has {
+			patch.Set(k, nil)
+		}
+	}
+
+	for k, bv := range b.All() {
+
+		av, has := a.Get(k)
+
+		am, aok := av.(Map[string, any])
+		bm, bok := bv.(Map[string, any])
+
+		switch {
+		case has && aok && bok && !am.IsNil() && !bm.IsNil():
+			if p := CreateMergePatch(am, bm); p.Len() > 0 {
+				patch.Set(k, p)
+			}
+		case !has || !equalValue(av, bv):
+			patch.Set(k, cloneValue(bv))
+		}
+	}
+
+	return patch
+}
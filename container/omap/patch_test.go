@@ -0,0 +1,302 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package omap_test
+
+import (
+	"encoding/json"
+	"errors"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/layer8co/toolbox/container/omap"
+)
+
+func TestApplyPatch(t *testing.T) {
+
+	// Mostly from the examples of RFC 6902.
+	tests := []struct {
+		line  int
+		doc   string
+		patch string
+		want  string
+		err   bool
+	}{
+		{
+			line:  line(),
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "add", "path": "/baz", "value": "qux"}]`,
+			want:  `{"foo":"bar","baz":"qux"}`,
+		},
+		{
+			line:  line(),
+			doc:   `{"foo": ["bar", "baz"]}`,
+			patch: `[{"op": "add", "path": "/foo/1", "value": "qux"}]`,
+			want:  `{"foo":["bar","qux","baz"]}`,
+		},
+		{
+			line:  line(),
+			doc:   `{"baz": "qux", "foo": "bar"}`,
+			patch: `[{"op": "remove", "path": "/baz"}]`,
+			want:  `{"foo":"bar"}`,
+		},
+		{
+			line:  line(),
+			doc:   `{"foo": ["bar", "qux", "baz"]}`,
+			patch: `[{"op": "remove", "path": "/foo/1"}]`,
+			want:  `{"foo":["bar","baz"]}`,
+		},
+		{
+			line:  line(),
+			doc:   `{"baz": "qux", "foo": "bar"}`,
+			patch: `[{"op": "replace", "path": "/baz", "value": "boo"}]`,
+			want:  `{"baz":"boo","foo":"bar"}`,
+		},
+		{
+			line:  line(),
+			doc:   `{"foo": {"bar": "baz", "waldo": "fred"}, "qux": {"corge": "grault"}}`,
+			patch: `[{"op": "move", "from": "/foo/waldo", "path": "/qux/thud"}]`,
+			want:  `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`,
+		},
+		{
+			line:  line(),
+			doc:   `{"foo": ["all", "grass", "cows", "eat"]}`,
+			patch: `[{"op": "move", "from": "/foo/1", "path": "/foo/3"}]`,
+			want:  `{"foo":["all","cows","eat","grass"]}`,
+		},
+		{
+			line:  line(),
+			doc:   `{"a": 1, "b": 2}`,
+			patch: `[{"op": "move", "from": "/a", "path": "/a"}]`,
+			want:  `{"b":2,"a":1}`,
+		},
+		{
+			line:  line(),
+			doc:   `{"a": {"b": [1]}}`,
+			patch: `[{"op": "copy", "from": "/a", "path": "/c"}, {"op": "add", "path": "/c/b/-", "value": 2}]`,
+			want:  `{"a":{"b":[1]},"c":{"b":[1,2]}}`,
+		},
+		{
+			line:  line(),
+			doc:   `{"baz": "qux", "foo": ["a", 2, "c"]}`,
+			patch: `[{"op": "test", "path": "/baz", "value": "qux"}, {"op": "test", "path": "/foo/1", "value": 2}]`,
+			want:  `{"baz":"qux","foo":["a",2,"c"]}`,
+		},
+		{
+			line:  line(),
+			doc:   `{"a/b": {"m~n": 1}}`,
+			patch: `[{"op": "test", "path": "", "value": {"a/b": {"m~n": 1}}}, {"op": "replace", "path": "/a~1b/m~0n", "value": 2}]`,
+			want:  `{"a/b":{"m~n":2}}`,
+		},
+		{
+			line:  line(),
+			doc:   `{"a": 1}`,
+			patch: `[{"op": "replace", "path": "", "value": {"b": null}}]`,
+			want:  `{"b":null}`,
+		},
+		{
+			line:  line(),
+			doc:   `{"baz": "qux"}`,
+			patch: `[{"op": "add", "path": "/x", "value": 1}, {"op": "test", "path": "/baz", "value": "bar"}]`,
+			err:   true,
+		},
+		{
+			line:  line(),
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "add", "path": "/baz/bat", "value": "qux"}]`,
+			err:   true,
+		},
+		{
+			line:  line(),
+			doc:   `{"foo": [1]}`,
+			patch: `[{"op": "add", "path": "/foo/01", "value": 2}]`,
+			err:   true,
+		},
+		{
+			line:  line(),
+			doc:   `{"a": {"b": 1}}`,
+			patch: `[{"op": "move", "from": "/a", "path": "/a/c"}]`,
+			err:   true,
+		},
+		{
+			line:  line(),
+			doc:   `{"a": 1}`,
+			patch: `[{"op": "replace", "path": "/b", "value": 1}]`,
+			err:   true,
+		},
+		{
+			line:  line(),
+			doc:   `{"a": 1}`,
+			patch: `[{"op": "replace", "path": "", "value": [1]}]`,
+			err:   true,
+		},
+	}
+
+	for _, test := range tests {
+
+		var patch omap.Patch
+		err := json.Unmarshal([]byte(test.patch), &patch)
+		if err != nil {
+			t.Fatalf("line %d: %v", test.line, err)
+		}
+
+		m := doc(t, test.doc)
+		orig := m.String()
+
+		err = omap.ApplyPatch(&m, patch)
+		if test.err {
+			if err == nil {
+				t.Errorf("line %d: expected error", test.line)
+			}
+			if m.String() != orig {
+				t.Errorf("line %d: document was modified by a failed patch: %v", test.line, m)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("line %d: unexpected error: %v", test.line, err)
+			continue
+		}
+
+		got, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(test.want, string(got)); diff != "" {
+			t.Errorf("line %d: incorrect result (-want +got):\n%s", test.line, diff)
+		}
+	}
+}
+
+func TestPatchTestFailed(t *testing.T) {
+	m := doc(t, `{"a": {"x": 1, "y": 2}}`)
+	err := omap.ApplyPatch(&m, omap.Patch{
+		{Op: "test", Path: "/a", Value: doc(t, `{"y": 2, "x": 1}`)},
+		{Op: "test", Path: "/a/x", Value: 1},
+		{Op: "test", Path: "/a/y", Value: 3},
+	})
+	if !errors.Is(err, omap.ErrTestFailed) {
+		t.Errorf("want ErrTestFailed, got %v", err)
+	}
+}
+
+func TestCreatePatch(t *testing.T) {
+
+	tests := []struct {
+		line int
+		a    string
+		b    string
+		want string
+	}{
+		{
+			line: line(),
+			a:    `{"a": 1, "b": 2}`,
+			b:    `{"a": 1, "b": 2}`,
+			want: `null`,
+		},
+		{
+			line: line(),
+			a:    `{"a": 1, "b": {"c": 2, "d": 3}, "e": 4}`,
+			b:    `{"a": 10, "b": {"c": 2}, "f": [5]}`,
+			want: `[{"op":"remove","path":"/e"},{"op":"replace","path":"/a","value":10},{"op":"remove","path":"/b/d"},{"op":"add","path":"/f","value":[5]}]`,
+		},
+		{
+			line: line(),
+			a:    `{"a": 1, "b": 2, "c": 3}`,
+			b:    `{"a": 1, "c": 3, "x": 0, "b": 2}`,
+			want: `[{"op":"add","path":"/x","value":0},{"op":"move","from":"/b","path":"/b"}]`,
+		},
+		{
+			line: line(),
+			a:    `{"a": 1, "b": 2, "c": 3}`,
+			b:    `{"c": 3, "b": 2, "a": 1}`,
+			want: `[{"op":"move","from":"/b","path":"/b"},{"op":"move","from":"/a","path":"/a"}]`,
+		},
+	}
+
+	for _, test := range tests {
+
+		a := doc(t, test.a)
+		b := doc(t, test.b)
+
+		patch := omap.CreatePatch(a, b)
+
+		got, err := json.Marshal(patch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(test.want, string(got)); diff != "" {
+			t.Errorf("line %d: incorrect patch (-want +got):\n%s", test.line, diff)
+		}
+
+		err = omap.ApplyPatch(&a, patch)
+		if err != nil {
+			t.Errorf("line %d: unexpected error: %v", test.line, err)
+		}
+		if diff := cmp.Diff(b.String(), a.String()); diff != "" {
+			t.Errorf("line %d: incorrect patched document (-want +got):\n%s", test.line, diff)
+		}
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+
+	// From the examples of RFC 7386.
+	tests := []struct {
+		line  int
+		doc   string
+		patch string
+		want  string
+	}{
+		{line(), `{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{line(), `{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{line(), `{"a":"b"}`, `{"a":null}`, `{}`},
+		{line(), `{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{line(), `{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{line(), `{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{line(), `{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{line(), `{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{line(), `{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{line(), `{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, test := range tests {
+		m := doc(t, test.doc)
+		omap.ApplyMergePatch(&m, doc(t, test.patch))
+		got, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(test.want, string(got)); diff != "" {
+			t.Errorf("line %d: incorrect result (-want +got):\n%s", test.line, diff)
+		}
+	}
+}
+
+func TestCreateMergePatch(t *testing.T) {
+
+	a := doc(t, `{"title": "Hello!", "author": {"givenName": "John", "familyName": "Doe"}, "tags": ["example", "sample"], "content": "x"}`)
+	b := doc(t, `{"title": "Hello!", "author": {"givenName": "John"}, "tags": ["example"], "content": "x", "phoneNumber": "+01-123-456-7890"}`)
+
+	patch := omap.CreateMergePatch(a, b)
+
+	got, err := json.Marshal(patch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"author":{"familyName":null},"tags":["example"],"phoneNumber":"+01-123-456-7890"}`
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("incorrect patch (-want +got):\n%s", diff)
+	}
+
+	omap.ApplyMergePatch(&a, patch)
+	if diff := cmp.Diff(b.String(), a.String()); diff != "" {
+		t.Errorf("incorrect patched document (-want +got):\n%s", diff)
+	}
+}
+
+func line() int {
+	_, _, line, _ := runtime.Caller(1)
+	return line
+}
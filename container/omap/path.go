@@ -0,0 +1,176 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package omap
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// GetPath returns the value at path in m,
+// which is typically a nested document decoded into a Map[string, any].
+//
+// A path is a dot-separated list of keys, like "server.tls.cert".
+// A key indexes into a Map[string, any],
+// or if it's an integer, into a []any.
+// Dots and backslashes in keys can be escaped with a backslash,
+// like "hosts.example\.com".
+func GetPath(m Map[string, any], path string) (val any, has bool) {
+
+	keys := splitPath(path)
+
+	var cur any = m
+	for _, key := range keys {
+		cur, has = child(cur, key)
+		if !has {
+			return nil, false
+		}
+	}
+
+	return cur, true
+}
+
+// SetPath sets the value at path in m
+// (see [GetPath] for the path syntax),
+// creating the maps leading up to it if they don't exist.
+//
+// It returns an error if a value on the way is neither a map nor a slice,
+// or if a slice index is out of range.
+func SetPath(m *Map[string, any], path string, val any) error {
+
+	Init(m)
+
+	keys := splitPath(path)
+	parent, err := walkPath(*m, keys, true)
+	if err != nil {
+		return err
+	}
+
+	key := keys[len(keys)-1]
+
+	switch p := parent.(type) {
+	case Map[string, any]:
+		p.Set(key, val)
+	case []any:
+		i, err := sliceIndex(p, key)
+		if err != nil {
+			return fmt.Errorf("%q: %w", path, err)
+		}
+		p[i] = val
+	}
+
+	return nil
+}
+
+// DeletePath deletes the value at path in m and returns it.
+// Elements of slices can not be deleted.
+func DeletePath(m Map[string, any], path string) (val any, has bool) {
+
+	keys := splitPath(path)
+	parent, err := walkPath(m, keys, false)
+	if err != nil {
+		return nil, false
+	}
+
+	p, ok := parent.(Map[string, any])
+	if !ok {
+		return nil, false
+	}
+
+	return p.Delete(keys[len(keys)-1])
+}
+
+// walkPath returns the parent of the value at keys,
+// which is either a Map[string, any] or a []any.
+// If create is true, missing maps are created.
+func walkPath(m Map[string, any], keys []string, create bool) (any, error) {
+
+	var cur any = m
+
+	for i, key := range keys[:len(keys)-1] {
+
+		next, has := child(cur, key)
+
+		if !has {
+			p, ok := cur.(Map[string, any])
+			if !ok || !create {
+				return nil, fmt.Errorf("%q does not exist", joinPath(keys[:i+1]))
+			}
+			n := New[string, any]()
+			p.Set(key, n)
+			next = n
+		}
+
+		switch next.(type) {
+		case Map[string, any], []any:
+		default:
+			return nil, fmt.Errorf(
+				"%q is a %T, not a map or slice",
+				joinPath(keys[:i+1]), next,
+			)
+		}
+
+		cur = next
+	}
+
+	return cur, nil
+}
+
+// child returns the value of key in v
+// if v is a Map[string, any] or a []any.
+func child(v any, key string) (any, bool) {
+	switch v := v.(type) {
+	case Map[string, any]:
+		return v.Get(key)
+	case []any:
+		i, err := sliceIndex(v, key)
+		if err != nil {
+			return nil, false
+		}
+		return v[i], true
+	}
+	return nil, false
+}
+
+func sliceIndex(s []any, key string) (int, error) {
+	i, err := strconv.Atoi(key)
+	if err != nil {
+		return 0, fmt.Errorf("invalid slice index %q", key)
+	}
+	if i < 0 || i >= len(s) {
+		return 0, fmt.Errorf("slice index %d out of range [0:%d]", i, len(s))
+	}
+	return i, nil
+}
+
+func splitPath(path string) []string {
+	var keys []string
+	var sb strings.Builder
+	escaped := false
+	for _, r := range path {
+		switch {
+		case escaped:
+			sb.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '.':
+			keys = append(keys, sb.String())
+			sb.Reset()
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return append(keys, sb.String())
+}
+
+func joinPath(keys []string) string {
+	r := strings.NewReplacer(`\`, `\\`, `.`, `\.`)
+	s := make([]string, len(keys))
+	for i, k := range keys {
+		s[i] = r.Replace(k)
+	}
+	return strings.Join(s, ".")
+}
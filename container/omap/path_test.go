@@ -0,0 +1,147 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package omap_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/layer8co/toolbox/container/omap"
+	"go.yaml.in/yaml/v4"
+)
+
+func TestNestedDecoding(t *testing.T) {
+
+	want := "omap[b:omap[z:1 y:[omap[q:1 p:2]]] a:2]"
+
+	var j omap.Map[string, any]
+	err := json.Unmarshal([]byte(`{"b": {"z": 1, "y": [{"q": 1, "p": 2}]}, "a": 2}`), &j)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, j.String()); diff != "" {
+		t.Errorf("json: incorrect result (-want +got):\n%s", diff)
+	}
+
+	var y omap.Map[string, any]
+	err = yaml.Unmarshal([]byte("b:\n  z: 1\n  y:\n    - q: 1\n      p: 2\na: 2\n"), &y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, y.String()); diff != "" {
+		t.Errorf("yaml: incorrect result (-want +got):\n%s", diff)
+	}
+}
+
+func TestYAMLMergeKeys(t *testing.T) {
+
+	in := "" +
+		"base: &b\n" +
+		"  x: 1\n" +
+		"  y: 2\n" +
+		"other:\n" +
+		"  <<: *b\n" +
+		"  y: 5\n" +
+		"  z: 3\n" +
+		"multi:\n" +
+		"  <<: [{w: 9, x: 7}, *b]\n"
+
+	want := "omap[base:omap[x:1 y:2] other:omap[x:1 y:5 z:3] multi:omap[w:9 x:7 y:2]]"
+
+	var m omap.Map[string, any]
+	err := yaml.Unmarshal([]byte(in), &m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, m.String()); diff != "" {
+		t.Errorf("incorrect result (-want +got):\n%s", diff)
+	}
+	if v, has := omap.GetPath(m, "other.x"); v != 1 || !has {
+		t.Errorf("GetPath: want 1, true, got %v, %v", v, has)
+	}
+
+	var typed omap.Map[string, omap.Map[string, int]]
+	err = yaml.Unmarshal([]byte(in), &typed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, typed.String()); diff != "" {
+		t.Errorf("typed: incorrect result (-want +got):\n%s", diff)
+	}
+
+	// Re-encoding keeps the meaning of the document.
+	out, err := yaml.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var again map[string]any
+	err = yaml.Unmarshal(out, &again)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var plain map[string]any
+	yaml.Unmarshal([]byte(in), &plain)
+	if diff := cmp.Diff(plain, again); diff != "" {
+		t.Errorf("re-encoded document differs (-want +got):\n%s\n%s", diff, out)
+	}
+
+	err = yaml.Unmarshal([]byte("a:\n  <<: 1\n"), &m)
+	if err == nil {
+		t.Error("expected error for merging a scalar")
+	}
+}
+
+func TestPath(t *testing.T) {
+
+	var m omap.Map[string, any]
+	err := m.DecodeJSON(strings.NewReader(`{"server": {"port": 80, "hosts": ["a", {"x": 1}]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v, has := omap.GetPath(m, "server.port"); !has || v != 80.0 {
+		t.Errorf("GetPath: want 80, true, got %v, %v", v, has)
+	}
+	if v, has := omap.GetPath(m, "server.hosts.1.x"); !has || v != 1.0 {
+		t.Errorf("GetPath: want 1, true, got %v, %v", v, has)
+	}
+	if _, has := omap.GetPath(m, "server.missing.x"); has {
+		t.Error("GetPath: missing path should not exist")
+	}
+
+	must(t, omap.SetPath(&m, "server.tls.cert", "c.pem"))
+	must(t, omap.SetPath(&m, "server.hosts.0", "b"))
+	must(t, omap.SetPath(&m, `domains.example\.com`, true))
+
+	if err := omap.SetPath(&m, "server.port.x", 1); err == nil {
+		t.Error("SetPath: expected error when descending into a number")
+	}
+	if err := omap.SetPath(&m, "server.hosts.5", 1); err == nil {
+		t.Error("SetPath: expected error for out of range index")
+	}
+
+	if v, has := omap.DeletePath(m, "server.port"); !has || v != 80.0 {
+		t.Errorf("DeletePath: want 80, true, got %v, %v", v, has)
+	}
+
+	want := "omap[server:omap[hosts:[b omap[x:1]] tls:omap[cert:c.pem]] domains:omap[example.com:true]]"
+	if diff := cmp.Diff(want, m.String()); diff != "" {
+		t.Errorf("incorrect result (-want +got):\n%s", diff)
+	}
+
+	var empty omap.Map[string, any]
+	must(t, omap.SetPath(&empty, "a.b", 1))
+	if diff := cmp.Diff("omap[a:omap[b:1]]", empty.String()); diff != "" {
+		t.Errorf("incorrect result (-want +got):\n%s", diff)
+	}
+}
+
+func must(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package omap
+
+import (
+	"encoding/json"
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// Set is an ordered set,
+// which keeps its elements in the order they were first added.
+//
+// The zero value is an empty set ready to use.
+// Like [Map], copies of a Set share the same elements.
+type Set[K comparable] struct {
+	m Map[K, struct{}]
+}
+
+// NewSet returns a set containing elems,
+// without duplicates.
+func NewSet[K comparable](elems ...K) Set[K] {
+	s := Set[K]{
+		m: New[K, struct{}](len(elems)),
+	}
+	s.Add(elems...)
+	return s
+}
+
+func (s Set[K]) IsNil() bool {
+	return s.m.IsNil()
+}
+
+// Add adds the elements that are not already in the set to its end.
+func (s *Set[K]) Add(elems ...K) {
+	Init(&s.m)
+	for _, e := range elems {
+		if s.m.index(e) == -1 {
+			s.m.s = append(s.m.s, tuple[K, struct{}]{key: e})
+		}
+	}
+}
+
+func (s Set[K]) Has(elem K) bool {
+	_, has := s.m.Get(elem)
+	return has
+}
+
+// Remove removes elems from the set.
+func (s *Set[K]) Remove(elems ...K) {
+	for _, e := range elems {
+		s.m.Delete(e)
+	}
+}
+
+func (s Set[K]) Len() int {
+	return s.m.Len()
+}
+
+func (s Set[K]) All() iter.Seq[K] {
+	return s.m.Keys()
+}
+
+// Slice returns the elements of the set in order.
+func (s Set[K]) Slice() []K {
+	if s.IsNil() {
+		return nil
+	}
+	x := make([]K, len(s.m.s))
+	for i, t := range s.m.s {
+		x[i] = t.key
+	}
+	return x
+}
+
+// Union returns a new set containing the elements of s,
+// followed by the elements of the others that are not in s, in order.
+func (s Set[K]) Union(others ...Set[K]) Set[K] {
+	u := NewSet(s.Slice()...)
+	for _, o := range others {
+		u.Add(o.Slice()...)
+	}
+	return u
+}
+
+// Intersect returns a new set containing the elements of s
+// that are in all of the others, in the order of s.
+func (s Set[K]) Intersect(others ...Set[K]) Set[K] {
+	return s.filter(func(e K) bool {
+		for _, o := range others {
+			if !o.Has(e) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// Difference returns a new set containing the elements of s
+// that are in none of the others, in the order of s.
+func (s Set[K]) Difference(others ...Set[K]) Set[K] {
+	return s.filter(func(e K) bool {
+		for _, o := range others {
+			if o.Has(e) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func (s Set[K]) filter(keep func(K) bool) Set[K] {
+	x := Set[K]{
+		m: New[K, struct{}](),
+	}
+	for e := range s.All() {
+		if keep(e) {
+			x.m.s = append(x.m.s, tuple[K, struct{}]{key: e})
+		}
+	}
+	return x
+}
+
+// Equal reports whether s and o have the same elements in the same order.
+// It allows go-cmp to compare sets.
+func (s Set[K]) Equal(o Set[K]) bool {
+	return slices.Equal(s.Slice(), o.Slice())
+}
+
+func (s Set[K]) String() string {
+	var sb strings.Builder
+	sb.WriteString("set[")
+	for i, e := range s.Slice() {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		fmt.Fprint(&sb, e)
+	}
+	sb.WriteString("]")
+	return sb.String()
+}
+
+// MarshalJSON encodes the set as an array.
+// A nil set is encoded as null.
+func (s Set[K]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Slice())
+}
+
+// UnmarshalJSON adds the elements of a JSON array to the set.
+// Duplicates are dropped.
+func (s *Set[K]) UnmarshalJSON(b []byte) error {
+	var x []K
+	err := json.Unmarshal(b, &x)
+	if err != nil {
+		return err
+	}
+	if x != nil {
+		s.Add(x...)
+	}
+	return nil
+}
+
+// MarshalYAML encodes the set as a sequence.
+func (s Set[K]) MarshalYAML() (any, error) {
+	x := s.Slice()
+	if x == nil {
+		x = []K{}
+	}
+	return x, nil
+}
+
+// UnmarshalYAML adds the elements of a YAML sequence to the set.
+// Duplicates are dropped.
+func (s *Set[K]) UnmarshalYAML(node *yaml.Node) error {
+	var x []K
+	err := node.Decode(&x)
+	if err != nil {
+		return err
+	}
+	s.Add(x...)
+	return nil
+}
@@ -0,0 +1,107 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package omap_test
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/layer8co/toolbox/container/omap"
+	"go.yaml.in/yaml/v4"
+)
+
+func TestSet(t *testing.T) {
+
+	var s omap.Set[string]
+	s.Add("c", "a", "c", "b")
+	s.Add("a")
+	s.Remove("x", "a")
+	s.Add("a")
+
+	if diff := cmp.Diff([]string{"c", "b", "a"}, slices.Collect(s.All())); diff != "" {
+		t.Errorf("incorrect elements (-want +got):\n%s", diff)
+	}
+	if !s.Has("b") || s.Has("x") || s.Len() != 3 {
+		t.Errorf("incorrect set: %v", s)
+	}
+
+	var nilSet omap.Set[string]
+	nilSet.Remove("a")
+	if nilSet.Has("a") || nilSet.Len() != 0 || nilSet.String() != "set[]" {
+		t.Errorf("incorrect nil set: %v", nilSet)
+	}
+}
+
+func TestSetAlgebra(t *testing.T) {
+
+	a := omap.NewSet(5, 1, 4, 2)
+	b := omap.NewSet(3, 4, 1)
+	c := omap.NewSet(4, 6)
+
+	tests := []struct {
+		name string
+		got  omap.Set[int]
+		want string
+	}{
+		{"union", a.Union(b, c), "set[5 1 4 2 3 6]"},
+		{"intersect", a.Intersect(b), "set[1 4]"},
+		{"intersect many", a.Intersect(b, c), "set[4]"},
+		{"difference", a.Difference(b), "set[5 2]"},
+		{"difference many", b.Difference(a, c), "set[3]"},
+		{"empty union", omap.Set[int]{}.Union(), "set[]"},
+	}
+
+	for _, test := range tests {
+		if diff := cmp.Diff(test.want, test.got.String()); diff != "" {
+			t.Errorf("%s: incorrect result (-want +got):\n%s", test.name, diff)
+		}
+	}
+
+	if diff := cmp.Diff("set[5 1 4 2]", a.String()); diff != "" {
+		t.Errorf("operands were modified (-want +got):\n%s", diff)
+	}
+}
+
+func TestSetMarshal(t *testing.T) {
+
+	var s omap.Set[string]
+	err := json.Unmarshal([]byte(`["b", "a", "b"]`), &s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	j, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(`["b","a"]`, string(j)); diff != "" {
+		t.Errorf("json: incorrect result (-want +got):\n%s", diff)
+	}
+
+	var y struct {
+		Tags omap.Set[string] `yaml:"tags"`
+	}
+	err = yaml.Unmarshal([]byte("tags: [z, y, z]\n"), &y)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := yaml.Marshal(y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff("tags:\n    - z\n    - \"y\"\n", string(b)); diff != "" {
+		t.Errorf("yaml: incorrect result (-want +got):\n%s", diff)
+	}
+
+	j, err = json.Marshal(omap.Set[int]{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff("null", string(j)); diff != "" {
+		t.Errorf("nil set: incorrect result (-want +got):\n%s", diff)
+	}
+}
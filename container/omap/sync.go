@@ -0,0 +1,148 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package omap
+
+import (
+	"iter"
+	"slices"
+	"sync"
+)
+
+// SyncMap is an ordered map that is safe for concurrent use
+// by multiple goroutines.
+//
+// The zero value is an empty map ready to use.
+// A SyncMap must not be copied after first use.
+type SyncMap[K comparable, V any] struct {
+	mu sync.RWMutex
+	m  Map[K, V]
+}
+
+// NewSyncMap returns a new [SyncMap].
+// size is the optional initial capacity of the map.
+func NewSyncMap[K comparable, V any](size ...int) *SyncMap[K, V] {
+	return &SyncMap[K, V]{
+		m: New[K, V](size...),
+	}
+}
+
+func (s *SyncMap[K, V]) Get(key K) (val V, has bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.Get(key)
+}
+
+func (s *SyncMap[K, V]) Set(key K, val V) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m.init()
+	s.m.Set(key, val)
+}
+
+func (s *SyncMap[K, V]) Delete(key K) (val V, has bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m.Delete(key)
+}
+
+func (s *SyncMap[K, V]) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.Len()
+}
+
+// LoadOrStore returns the existing value for the key if present.
+// Otherwise, it stores and returns the given value.
+// The loaded result is true if the value was loaded, false if stored.
+func (s *SyncMap[K, V]) LoadOrStore(key K, val V) (actual V, loaded bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m.init()
+	if v, ok := s.m.Get(key); ok {
+		return v, true
+	}
+	s.m.Set(key, val)
+	return val, false
+}
+
+// CompareAndSwap swaps the old and new values for key
+// if the value stored in the map is equal to old.
+//
+// Like [sync.Map.CompareAndSwap], V must be a comparable type at runtime,
+// otherwise CompareAndSwap panics.
+func (s *SyncMap[K, V]) CompareAndSwap(key K, old, new V) (swapped bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.index(key)
+	if i == -1 || any(s.m.s[i].val) != any(old) {
+		return false
+	}
+	s.m.s[i].val = new
+	return true
+}
+
+// Update atomically sets the value of key to the result of fn,
+// which is called with the current value of key
+// and whether the key was present.
+// It returns the new value.
+//
+// fn is called with the map locked,
+// so it must not access the map itself.
+func (s *SyncMap[K, V]) Update(key K, fn func(old V, has bool) V) V {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m.init()
+	old, has := s.m.Get(key)
+	val := fn(old, has)
+	s.m.Set(key, val)
+	return val
+}
+
+// Snapshot returns a copy of the map.
+func (s *SyncMap[K, V]) Snapshot() Map[K, V] {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	m := New[K, V]()
+	if !s.m.IsNil() {
+		m.s = slices.Clone(s.m.s)
+	}
+	return m
+}
+
+// All returns an iterator over the entries of the map
+// as they were when iteration started.
+// The map is not locked during iteration,
+// so it's safe to modify the map from within the loop.
+func (s *SyncMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		s.Snapshot().All()(yield)
+	}
+}
+
+// Keys is similar to [SyncMap.All] but only iterates over the keys.
+func (s *SyncMap[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		s.Snapshot().Keys()(yield)
+	}
+}
+
+// Values is similar to [SyncMap.All] but only iterates over the values.
+func (s *SyncMap[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		s.Snapshot().Values()(yield)
+	}
+}
+
+func (s *SyncMap[K, V]) String() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.String()
+}
+
+func (s *SyncMap[K, V]) index(key K) int {
+	if s.m.IsNil() {
+		return -1
+	}
+	return s.m.index(key)
+}
@@ -0,0 +1,109 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package omap_test
+
+import (
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/layer8co/toolbox/container/omap"
+)
+
+func TestSyncMap(t *testing.T) {
+
+	var m omap.SyncMap[string, int]
+
+	if _, has := m.Get("a"); has {
+		t.Fatal("zero map should be empty")
+	}
+
+	m.Set("a", 1)
+	m.Set("b", 2)
+	m.Set("c", 3)
+	m.Set("a", 4)
+
+	if v, loaded := m.LoadOrStore("b", 5); !loaded || v != 2 {
+		t.Errorf("LoadOrStore existing: want 2, true, got %v, %v", v, loaded)
+	}
+	if v, loaded := m.LoadOrStore("d", 5); loaded || v != 5 {
+		t.Errorf("LoadOrStore new: want 5, false, got %v, %v", v, loaded)
+	}
+
+	if m.CompareAndSwap("c", 0, 6) {
+		t.Error("CompareAndSwap should fail on mismatched old value")
+	}
+	if !m.CompareAndSwap("c", 3, 6) {
+		t.Error("CompareAndSwap should succeed on matching old value")
+	}
+	if m.CompareAndSwap("x", 0, 1) {
+		t.Error("CompareAndSwap should fail on missing key")
+	}
+
+	if v, has := m.Delete("b"); !has || v != 2 {
+		t.Errorf("Delete: want 2, true, got %v, %v", v, has)
+	}
+
+	want := "omap[a:4 c:6 d:5]"
+	got := m.String()
+	if want != got {
+		t.Errorf("incorrect map: want %q, got %q", want, got)
+	}
+}
+
+func TestSyncMapAllSnapshot(t *testing.T) {
+
+	m := omap.NewSyncMap[int, int]()
+	for i := range 5 {
+		m.Set(i, i)
+	}
+
+	var keys []int
+	for k := range m.All() {
+		keys = append(keys, k)
+		m.Delete(k + 1)
+		m.Set(k+10, k)
+	}
+
+	want := []int{0, 1, 2, 3, 4}
+	if !slices.Equal(want, keys) {
+		t.Errorf("incorrect iteration: want %v, got %v", want, keys)
+	}
+}
+
+func TestSyncMapConcurrent(t *testing.T) {
+
+	const (
+		goroutines = 8
+		iterations = 1000
+	)
+
+	var m omap.SyncMap[int, int]
+	var wg sync.WaitGroup
+
+	for g := range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range iterations {
+				m.Update(-1, func(old int, _ bool) int {
+					return old + 1
+				})
+				m.LoadOrStore(i, g)
+				m.Set(i%10, i)
+				m.Get(i)
+				for range m.All() {
+					break
+				}
+				m.Delete(i % 7)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if v, _ := m.Get(-1); v != goroutines*iterations {
+		t.Errorf("lost updates: want %d, got %d", goroutines*iterations, v)
+	}
+}
@@ -5,10 +5,26 @@ package omap
 
 import (
 	"fmt"
+	"io"
+	"reflect"
 
 	"go.yaml.in/yaml/v4"
 )
 
+// yamlEntry holds the nodes an entry was decoded from,
+// so that their comments, styles and anchors can be re-emitted.
+type yamlEntry struct {
+	key *yaml.Node
+	val *yaml.Node
+}
+
+// MarshalYAML implements [yaml.Marshaler].
+//
+// Entries that were decoded by [Map.UnmarshalYAML]
+// are re-emitted with their original comments, and as long as
+// their values are unchanged, their original styles, tags, anchors and aliases.
+// Comments are kept even if the value of the entry changes.
+// Merge keys ("<<") are re-emitted as the entries they merged.
 func (m Map[K, V]) MarshalYAML() (any, error) {
 
 	node := &yaml.Node{
@@ -19,6 +35,12 @@ func (m Map[K, V]) MarshalYAML() (any, error) {
 		return node, nil
 	}
 
+	if m.yaml != nil {
+		copyYAMLMeta(node, m.yaml)
+	}
+
+	anchors := map[string]*yaml.Node{}
+
 	for _, t := range m.s {
 
 		key := &yaml.Node{}
@@ -34,37 +56,375 @@ func (m Map[K, V]) MarshalYAML() (any, error) {
 			return nil, err
 		}
 
+		if t.yaml != nil {
+			key = mergeYAMLNode(key, t.yaml.key, anchors)
+			val = mergeYAMLNode(val, t.yaml.val, anchors)
+		}
+
+		collectYAMLAnchors(key, anchors)
+		collectYAMLAnchors(val, anchors)
+
 		node.Content = append(node.Content, key, val)
 	}
 
+	// A head comment on the mapping node is emitted
+	// right above the head comment of its first key,
+	// so keep them apart like the parser found them.
+	if node.HeadComment != "" && len(node.Content) > 0 && node.Content[0].HeadComment != "" {
+		first := *node.Content[0]
+		first.HeadComment = joinYAMLComments(node.HeadComment, first.HeadComment)
+		node.Content[0] = &first
+		node.HeadComment = ""
+	}
+
 	return node, nil
 }
 
+// UnmarshalYAML implements [yaml.Unmarshaler]
+// using the default options of [Map.DecodeYAML].
+//
+// The key and value nodes of each entry are retained
+// so that [Map.MarshalYAML] can re-emit their comments, styles and anchors.
+// The comments of the enclosing document, if any, are kept on the map itself.
 func (m *Map[K, V]) UnmarshalYAML(node *yaml.Node) error {
+	return m.decodeYAML(node, &decodeConfig{})
+}
+
+// DecodeYAML reads the next YAML document from r
+// and stores its entries in the map.
+// See [Map.UnmarshalYAML] for details.
+func (m *Map[K, V]) DecodeYAML(r io.Reader, options ...DecodeOption) error {
+
+	c := &decodeConfig{}
+	for _, fn := range options {
+		fn(c)
+	}
+
+	var node yaml.Node
+	err := yaml.NewDecoder(r).Decode(&node)
+	if err != nil {
+		return err
+	}
+
+	return m.decodeYAML(&node, c)
+}
+
+type yamlDestreamer interface {
+	decodeYAML(node *yaml.Node, c *decodeConfig) error
+}
+
+func (m *Map[K, V]) decodeYAML(node *yaml.Node, c *decodeConfig) error {
+
+	err := checkDecodeConfig[V](c)
+	if err != nil {
+		return err
+	}
 
 	m.init()
 
+	meta := &yaml.Node{}
+
+	if node.Kind == yaml.DocumentNode && len(node.Content) == 1 {
+		copyYAMLComments(meta, node)
+		node = node.Content[0]
+	}
+
 	if node.Kind != yaml.MappingNode {
 		return fmt.Errorf("expected yaml mapping node, got %v", node.Kind)
 	}
 
-	for i := 0; i < len(node.Content); i += 2 {
+	meta.HeadComment = joinYAMLComments(meta.HeadComment, node.HeadComment)
+	meta.LineComment = joinYAMLComments(meta.LineComment, node.LineComment)
+	meta.FootComment = joinYAMLComments(node.FootComment, meta.FootComment)
+	meta.Style = node.Style
+	meta.Tag = node.Tag
+	m.yaml = meta
+
+	pairs, err := resolveYAMLMerges(node)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[K]struct{})
+
+	for _, p := range pairs {
+
+		keyNode := p.key
+		valNode := p.val
 
 		var key K
-		var val V
 
-		err := node.Content[i].Decode(&key)
+		err := keyNode.Decode(&key)
 		if err != nil {
 			return err
 		}
 
-		err = node.Content[i+1].Decode(&val)
+		_, had := seen[key]
+
+		err = m.decodeEntry(
+			c,
+			seen,
+			key,
+			func(v any) error {
+				switch v := v.(type) {
+				case yamlDestreamer:
+					return v.decodeYAML(valNode, c)
+				case *any:
+					return decodeYAMLAny(valNode, c, v)
+				}
+				if c.disallowUnknownFields {
+					return valNode.Load(v, yaml.WithKnownFields())
+				}
+				return valNode.Decode(v)
+			},
+			func() error {
+				return nil
+			},
+			func() error {
+				return &DuplicateKeyError{
+					Key:    keyNode.Value,
+					Line:   keyNode.Line,
+					Column: keyNode.Column,
+				}
+			},
+		)
 		if err != nil {
 			return err
 		}
 
-		m.Set(key, val)
+		// Merged entries are emitted as regular entries,
+		// without the metadata of the mapping they were merged from.
+		if !p.merged && (!had || c.duplicateKeys == DuplicateKeysLast) {
+			m.s[m.index(key)].yaml = &yamlEntry{
+				key: keyNode,
+				val: valNode,
+			}
+		}
 	}
 
 	return nil
 }
+
+// yamlPair is an entry of a YAML mapping node.
+type yamlPair struct {
+	key    *yaml.Node
+	val    *yaml.Node
+	merged bool
+}
+
+// resolveYAMLMerges returns the entries of the mapping node,
+// with merge keys ("<<") replaced by the entries of the mappings they merge.
+// Like [yaml.Node.Decode], explicit keys take precedence over merged ones,
+// and mappings earlier in a merged sequence over later ones.
+func resolveYAMLMerges(node *yaml.Node) ([]yamlPair, error) {
+
+	explicit := make(map[string]bool)
+	for i := 0; i < len(node.Content); i += 2 {
+		if k := node.Content[i]; !isYAMLMerge(k) {
+			explicit[k.Value] = true
+		}
+	}
+
+	var pairs []yamlPair
+	merged := make(map[string]bool)
+
+	for i := 0; i < len(node.Content); i += 2 {
+
+		keyNode := node.Content[i]
+		valNode := node.Content[i+1]
+
+		if !isYAMLMerge(keyNode) {
+			pairs = append(pairs, yamlPair{key: keyNode, val: valNode})
+			continue
+		}
+
+		sources := []*yaml.Node{valNode}
+		if valNode.Kind == yaml.SequenceNode {
+			sources = valNode.Content
+		}
+
+		for _, src := range sources {
+
+			if src.Kind == yaml.AliasNode {
+				src = src.Alias
+			}
+			if src == nil || src.Kind != yaml.MappingNode {
+				return nil, fmt.Errorf(
+					"line %d: map merge requires a mapping or a sequence of mappings",
+					valNode.Line,
+				)
+			}
+
+			srcPairs, err := resolveYAMLMerges(src)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, p := range srcPairs {
+				if explicit[p.key.Value] || merged[p.key.Value] {
+					continue
+				}
+				merged[p.key.Value] = true
+				p.merged = true
+				pairs = append(pairs, p)
+			}
+		}
+	}
+
+	return pairs, nil
+}
+
+func isYAMLMerge(n *yaml.Node) bool {
+	return n.Kind == yaml.ScalarNode && n.ShortTag() == "!!merge"
+}
+
+// decodeYAMLAny decodes node into v like [yaml.Node.Decode] would,
+// except that mappings are decoded into ordered Map[string, any] values
+// and sequences into []any.
+func decodeYAMLAny(node *yaml.Node, c *decodeConfig, v *any) error {
+
+	switch node.Kind {
+
+	case yaml.DocumentNode:
+		if len(node.Content) == 1 {
+			return decodeYAMLAny(node.Content[0], c, v)
+		}
+
+	case yaml.AliasNode:
+		return decodeYAMLAny(node.Alias, c, v)
+
+	case yaml.MappingNode:
+		m := New[string, any]()
+		err := m.decodeYAML(node, c.nested())
+		if err != nil {
+			return err
+		}
+		*v = m
+		return nil
+
+	case yaml.SequenceNode:
+		s := make([]any, len(node.Content))
+		for i, e := range node.Content {
+			err := decodeYAMLAny(e, c, &s[i])
+			if err != nil {
+				return err
+			}
+		}
+		*v = s
+		return nil
+	}
+
+	return node.Decode(v)
+}
+
+// mergeYAMLNode applies the metadata of the original node orig
+// to the freshly encoded node n and returns the node to be emitted.
+//
+// anchors holds the anchors emitted so far,
+// which aliases in orig can refer to.
+func mergeYAMLNode(n, orig *yaml.Node, anchors map[string]*yaml.Node) *yaml.Node {
+
+	if orig.Kind == yaml.AliasNode {
+		if target, ok := anchors[orig.Value]; ok && equalYAMLValue(n, target) {
+			alias := *orig
+			alias.Alias = target
+			return &alias
+		}
+		copyYAMLComments(n, orig)
+		return n
+	}
+
+	copyYAMLComments(n, orig)
+
+	if n.Kind != orig.Kind {
+		return n
+	}
+
+	switch n.Kind {
+
+	case yaml.ScalarNode:
+		if n.Value == orig.Value && n.ShortTag() == orig.ShortTag() {
+			n.Style = orig.Style
+			n.Tag = orig.Tag
+		}
+
+	case yaml.SequenceNode:
+		n.Style = orig.Style
+		for i := range min(len(n.Content), len(orig.Content)) {
+			n.Content[i] = mergeYAMLNode(n.Content[i], orig.Content[i], anchors)
+		}
+
+	case yaml.MappingNode:
+		n.Style = orig.Style
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			j := indexYAMLKey(orig, n.Content[i])
+			if j == -1 {
+				continue
+			}
+			n.Content[i] = mergeYAMLNode(n.Content[i], orig.Content[j], anchors)
+			n.Content[i+1] = mergeYAMLNode(n.Content[i+1], orig.Content[j+1], anchors)
+		}
+	}
+
+	if orig.Anchor != "" {
+		if _, taken := anchors[orig.Anchor]; !taken {
+			n.Anchor = orig.Anchor
+		}
+	}
+	if n.Anchor != "" {
+		anchors[n.Anchor] = n
+	}
+
+	return n
+}
+
+// indexYAMLKey returns the index of the scalar key in the mapping node m,
+// or -1 if it's not present.
+func indexYAMLKey(m, key *yaml.Node) int {
+	if key.Kind != yaml.ScalarNode {
+		return -1
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		k := m.Content[i]
+		if k.Kind == yaml.ScalarNode && k.Value == key.Value && k.ShortTag() == key.ShortTag() {
+			return i
+		}
+	}
+	return -1
+}
+
+func collectYAMLAnchors(n *yaml.Node, anchors map[string]*yaml.Node) {
+	if n.Anchor != "" {
+		anchors[n.Anchor] = n
+	}
+	for _, c := range n.Content {
+		collectYAMLAnchors(c, anchors)
+	}
+}
+
+func equalYAMLValue(a, b *yaml.Node) bool {
+	var x, y any
+	if a.Decode(&x) != nil || b.Decode(&y) != nil {
+		return false
+	}
+	return reflect.DeepEqual(x, y)
+}
+
+func copyYAMLComments(dst, src *yaml.Node) {
+	dst.HeadComment = src.HeadComment
+	dst.LineComment = src.LineComment
+	dst.FootComment = src.FootComment
+}
+
+func joinYAMLComments(a, b string) string {
+	if a == "" || b == "" {
+		return a + b
+	}
+	return a + "\n\n" + b
+}
+
+func copyYAMLMeta(dst, src *yaml.Node) {
+	copyYAMLComments(dst, src)
+	dst.Style = src.Style
+	dst.Tag = src.Tag
+}
@@ -0,0 +1,108 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package omap_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/layer8co/toolbox/container/omap"
+	"go.yaml.in/yaml/v4"
+)
+
+func TestYAMLRoundTrip(t *testing.T) {
+
+	tests := []struct {
+		name string
+		in   string
+		edit func(m *omap.Map[string, any])
+		want string
+	}{
+		{
+			"unchanged",
+			"" +
+				"# head a\n" +
+				"a: 1 # line a\n" +
+				"b: &x 'quoted' # line b\n" +
+				"# foot b\n" +
+				"\n" +
+				"c: *x\n" +
+				"d: {x: 1, y: [1, 2]}\n" +
+				"e: |\n" +
+				"    literal\n",
+			func(m *omap.Map[string, any]) {},
+			"" +
+				"# head a\n" +
+				"a: 1 # line a\n" +
+				"b: &x 'quoted' # line b\n" +
+				"# foot b\n" +
+				"\n" +
+				"c: *x\n" +
+				"d: {x: 1, y: [1, 2]}\n" +
+				"e: |\n" +
+				"    literal\n",
+		},
+		{
+			"edited",
+			"" +
+				"# head a\n" +
+				"a: 1 # line a\n" +
+				"b: &x 'quoted'\n" +
+				"c: *x\n",
+			func(m *omap.Map[string, any]) {
+				m.Set("a", 2)
+				m.Set("b", "changed")
+				m.Set("z", "new")
+			},
+			"" +
+				"# head a\n" +
+				"a: 2 # line a\n" +
+				"b: &x changed\n" +
+				"c: quoted\n" +
+				"z: new\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			var m omap.Map[string, any]
+			if err := yaml.Unmarshal([]byte(test.in), &m); err != nil {
+				t.Fatal(err)
+			}
+
+			test.edit(&m)
+
+			out, err := yaml.Marshal(m)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(test.want, string(out)); diff != "" {
+				t.Errorf("incorrect result (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestYAMLDocumentComments(t *testing.T) {
+
+	in := "# top\n\n# head a\na: 1\n"
+
+	var m omap.Map[string, int]
+	if err := yaml.Unmarshal([]byte(in), &m); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := yaml.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := in
+	got := string(out)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("incorrect result (-want +got):\n%s", diff)
+	}
+}
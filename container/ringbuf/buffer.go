@@ -11,32 +11,38 @@ import (
 	"slices"
 )
 
-// TODO:
-//
-//   - Add a Truncate() method.
-//       Of course if cap hasn't been reached, truncation is super easy.
-//       Otherwise, if the buffer ain't that full,
-//       we can shift things around in the buffer itself.
-//       If it's really full, put the extra shit
-//       in a lil buffer you got from a pool.
-//
-//   - Add the ability to lower the cap of the buffer.
-//       of course if the new cap(r.buf) is less than len(r.buf),
-//       we need to jettison SOME data.
-//       Do we jettison the newest data? The oldest data?
-//
-//   - Add Seek().
-//
-//   - Add Peek()? https://go.dev/doc/go1.26#bytespkgbytes
-
-var ErrNegativeOffset = errors.New("negative offset")
+var (
+	ErrNegativeOffset = errors.New("negative offset")
+	ErrOutOfRange     = errors.New("offset out of range")
+	ErrUnread         = errors.New("no read data to unread")
+	ErrOverwritten    = errors.New("data at offset has been overwritten")
+)
 
 type Buffer[T any] struct {
 	buf      []T
 	maxLen   int
 	writePos int // writePos is an actual position in buf.
 	readPos  int // readPos is relative to writePos. It wraps around buf.
+	written  int64
 	byteBuf  [1]T
+
+	overwritten int64
+	onOverwrite func(dropped []T)
+	marker      func(overwritten int64) []T
+
+	// Truncations of the buffer, which independent readers catch up on.
+	// See [Buffer.truncated].
+	truncGen  uint64
+	truncs    []truncation
+	prunedGen uint64
+	prunedOff int64
+}
+
+// truncation records that the buffer was truncated to offset off,
+// so that the elements written from there on replace the dropped ones.
+type truncation struct {
+	gen uint64
+	off int64
 }
 
 // NewBuffer returns a new ring buffer.
@@ -57,8 +63,19 @@ func NewBuffer[T any](maxLen int, initialCap ...int) *Buffer[T] {
 func (b *Buffer[T]) Write(src []T) (n int, err error) {
 
 	n = len(src)
+	b.written += int64(n)
+
+	if len(src) == 0 {
+		return n, nil
+	}
+
+	// The read position stays at the same element unless it's overwritten.
+	if drop := len(b.buf) + len(src) - b.maxLen; drop > 0 {
+		b.overwrite(drop, src)
+		b.readPos = max(b.readPos-drop, 0)
+	}
 
-	if len(src) == 0 || b.maxLen == 0 {
+	if b.maxLen == 0 {
 		return n, nil
 	}
 
@@ -86,6 +103,27 @@ func (b *Buffer[T]) Write(src []T) (n int, err error) {
 	return n, nil
 }
 
+// overwrite accounts for the n oldest elements
+// of the buffer followed by src being dropped,
+// and passes them to the overwrite hook.
+func (b *Buffer[T]) overwrite(n int, src []T) {
+	b.overwritten += int64(n)
+	if b.onOverwrite == nil {
+		return
+	}
+	for s := range b.seq(0) {
+		if n == 0 {
+			break
+		}
+		s = s[:min(len(s), n)]
+		b.onOverwrite(s)
+		n -= len(s)
+	}
+	if n > 0 {
+		b.onOverwrite(src[:n])
+	}
+}
+
 func (b *Buffer[T]) WriteByte(v T) error {
 	b.byteBuf[0] = v
 	b.Write(b.byteBuf[:])
@@ -93,7 +131,7 @@ func (b *Buffer[T]) WriteByte(v T) error {
 }
 
 func (b *Buffer[T]) Read(dest []T) (int, error) {
-	n, err := b.ReadAt(dest, int64(b.readPos))
+	n, err := b.readAt(dest, b.readPos)
 	b.readPos += n
 	return n, err
 }
@@ -103,17 +141,38 @@ func (b *Buffer[T]) ReadByte() (T, error) {
 	return b.byteBuf[0], err
 }
 
+// ReadAt implements [io.ReaderAt] over the stream of elements
+// written to the buffer, where offset 0 is the first element ever written.
+// It returns [ErrOverwritten] if the element at offset is no longer retained,
+// and [io.EOF] if it reads fewer than len(dest) elements.
 func (b *Buffer[T]) ReadAt(dest []T, offset int64) (n int, err error) {
 	if offset < 0 {
 		return 0, ErrNegativeOffset
 	}
-	if offset >= int64(len(b.buf)) {
+	start := b.Start()
+	if offset < start {
+		return 0, ErrOverwritten
+	}
+	if len(dest) == 0 {
+		return 0, nil
+	}
+	n, err = b.readAt(dest, int(min(offset-start, int64(len(b.buf)))))
+	if err == nil && n < len(dest) {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// readAt is like [Buffer.ReadAt],
+// but offset 0 is the oldest element the buffer retains.
+func (b *Buffer[T]) readAt(dest []T, offset int) (n int, err error) {
+	if offset >= len(b.buf) {
 		return 0, io.EOF
 	}
 	if len(dest) == 0 {
 		return 0, nil
 	}
-	for b := range b.seq(int(offset)) {
+	for b := range b.seq(offset) {
 		m := copy(dest, b)
 		dest = dest[m:]
 		n += m
@@ -121,13 +180,20 @@ func (b *Buffer[T]) ReadAt(dest []T, offset int64) (n int, err error) {
 	return n, nil
 }
 
-// Bytes returns a copy of the unread elements of the buffer.
+// Bytes returns a copy of the unread elements of the buffer,
+// preceded by the truncation marker if one is set
+// and elements have been overwritten (see [Buffer.SetTruncationMarker]).
 //
 // If you want to access buffer data without copying/allocation,
 // consider using [Buffer.BytesSeq].
 func (r *Buffer[T]) Bytes() []T {
-	b := make([]T, r.Len())
-	r.ReadAt(b, int64(r.readPos))
+	var m []T
+	if r.marker != nil && r.overwritten > 0 {
+		m = r.marker(r.overwritten)
+	}
+	b := make([]T, len(m)+r.Len())
+	copy(b, m)
+	r.readAt(b[len(m):], r.readPos)
 	return b
 }
 
@@ -166,12 +232,12 @@ func (b *Buffer[T]) NextSeq(n int) iter.Seq[[]T] {
 				break
 			}
 			s = s[:min(len(s), n)]
+			b.readPos += len(s)
+			n -= len(s)
 			if !yield(s) {
 				break
 			}
-			n -= len(s)
 		}
-		b.readPos += n
 	}
 }
 
@@ -195,46 +261,308 @@ func (b *Buffer[T]) seq(offset int) iter.Seq[[]T] {
 		}
 
 		offset = max(offset-len(s), 0)
-		s = b.buf[offset:b.writePos]
-		if len(s) > 0 && !yield(s[offset:]) {
+		s = b.buf[:b.writePos]
+		if offset < len(s) && !yield(s[offset:]) {
 			return
 		}
 	}
 }
 
-// // Truncate discards all but the first unread bytes from the buffer
-// // but continues to use the same allocated storage.
-// //
-// // It panics if n is negative or greater than r.Len().
-// func (r *Buffer[T]) Truncate(n int) {
-// 	if n < 0 {
-// 		panic("ringbuf.Buffer.Truncate: n < 0")
-// 	}
-// 	if n > r.Len() {
-// 		panic("ringbuf.Buffer.Truncate: n > r.Len()")
-// 	}
-// 	if n == r.Len() {
-// 		return
-// 	}
-// 	if r.writePos == 0 {
-// 		r.buf = r.buf[:r.readPos+n]
-// 		return
-// 	}
-// 	if end := r.writePos + r.readPos + n; end < len(r.buf) && /* overflow check: */ end > 0 {
-// 		copy(r.buf, r.buf[r.writePos:n])
-// 		r.buf = r.buf[:n-r.writePos]
-// 		return
-// 	}
-// }
+// Peek returns a copy of the next n unread elements of the buffer
+// without advancing it.
+// If there are fewer than n unread elements,
+// it returns all of them along with [io.EOF].
+//
+// If you want to access this data without allocations,
+// consider using [Buffer.BytesSeq].
+func (b *Buffer[T]) Peek(n int) ([]T, error) {
+	if n < 0 {
+		panic("ringbuf.Buffer.Peek: n < 0")
+	}
+	s := make([]T, min(n, b.Len()))
+	b.readAt(s, b.readPos)
+	if len(s) < n {
+		return s, io.EOF
+	}
+	return s, nil
+}
+
+// Discard skips the next n unread elements of the buffer,
+// returning the number of elements discarded.
+// If there are fewer than n unread elements,
+// it discards all of them and returns [io.EOF].
+func (b *Buffer[T]) Discard(n int) (discarded int, err error) {
+	if n < 0 {
+		panic("ringbuf.Buffer.Discard: n < 0")
+	}
+	discarded = min(n, b.Len())
+	b.readPos += discarded
+	if discarded < n {
+		return discarded, io.EOF
+	}
+	return discarded, nil
+}
+
+// Seek implements [io.Seeker] over the stream of elements
+// written to the buffer, setting the position of the next read.
+// Offsets are the same as with [Buffer.ReadAt].
+//
+// The resulting position must be between
+// [Buffer.Start] and [Buffer.Written], inclusive;
+// it returns [ErrOverwritten] if it's before the oldest retained element.
+func (b *Buffer[T]) Seek(offset int64, whence int) (int64, error) {
+
+	start := b.Start()
+
+	var abs int64
+	switch whence {
+	case io.SeekStart:
+		abs = offset
+	case io.SeekCurrent:
+		abs = start + int64(b.readPos) + offset
+	case io.SeekEnd:
+		abs = b.written + offset
+	default:
+		return 0, errors.New("ringbuf.Buffer.Seek: invalid whence")
+	}
+
+	switch {
+	case abs < 0:
+		return 0, ErrNegativeOffset
+	case abs < start:
+		return 0, ErrOverwritten
+	case abs > b.written:
+		return 0, ErrOutOfRange
+	}
+
+	b.readPos = int(abs - start)
+	return abs, nil
+}
+
+// UnreadByte unreads the last element that was read,
+// or more generally, the element before the read position.
+// Unlike [bytes.Buffer.UnreadByte],
+// it can be called repeatedly as long as the buffer retains the data.
+func (b *Buffer[T]) UnreadByte() error {
+	if b.readPos == 0 {
+		return ErrUnread
+	}
+	b.readPos--
+	return nil
+}
+
+// Truncate discards all but the first n unread elements of the buffer,
+// continuing to use the same allocated storage.
+// Read elements that the buffer retains are kept.
+//
+// It panics if n is negative or greater than the length of the buffer.
+func (b *Buffer[T]) Truncate(n int) {
+
+	if n < 0 {
+		panic("ringbuf.Buffer.Truncate: n < 0")
+	}
+	if n > b.Len() {
+		panic("ringbuf.Buffer.Truncate: n > b.Len()")
+	}
+	if n == b.Len() {
+		return
+	}
+
+	b.linearize()
+
+	end := b.readPos + n
+	b.written -= int64(len(b.buf) - end)
+	clear(b.buf[end:])
+	b.buf = b.buf[:end]
+	b.truncated()
+}
+
+// truncated records that the elements from [Buffer.Written] on
+// have been dropped from the end of the buffer.
+//
+// Only the lowest offset truncated to since a given generation matters
+// to a reader, so truncations to offsets that are not lower than
+// a later one are forgotten, as are truncations before [Buffer.Start].
+func (b *Buffer[T]) truncated() {
+
+	b.truncGen++
+
+	i := len(b.truncs)
+	for i > 0 && b.truncs[i-1].off >= b.written {
+		i--
+	}
+	b.truncs = append(b.truncs[:i], truncation{b.truncGen, b.written})
+
+	start := b.Start()
+	for len(b.truncs) > 0 && b.truncs[0].off < start {
+		b.prunedGen = b.truncs[0].gen
+		b.prunedOff = b.truncs[0].off
+		b.truncs = b.truncs[1:]
+	}
+}
+
+// truncatedSince returns the lowest offset
+// the buffer has been truncated to since truncation generation gen,
+// and false if it hasn't been truncated since.
+func (b *Buffer[T]) truncatedSince(gen uint64) (int64, bool) {
+	if gen < b.prunedGen {
+		return b.prunedOff, true
+	}
+	i := slices.IndexFunc(b.truncs, func(t truncation) bool {
+		return t.gen > gen
+	})
+	if i == -1 {
+		return 0, false
+	}
+	return b.truncs[i].off, true
+}
+
+// DropPolicy is used by [Buffer.SetMaxLen].
+type DropPolicy uint8
+
+const (
+	DropOldest DropPolicy = iota
+	DropNewest
+)
+
+// SetMaxLen changes the maximum length of the buffer to n.
+//
+// If the buffer holds more than n elements,
+// the oldest or newest ones are dropped according to policy,
+// and the retained elements are moved to a new backing array
+// so that the old one can be reclaimed.
+// The read position is adjusted to point at the same element
+// if it's still retained.
+// Dropping the oldest elements counts as overwriting them,
+// like writes do; see [Buffer.OnOverwrite].
+//
+// It panics if n is negative.
+func (b *Buffer[T]) SetMaxLen(n int, policy DropPolicy) {
+
+	if n < 0 {
+		panic("ringbuf.Buffer.SetMaxLen: n < 0")
+	}
+
+	// Writes only append to a buffer that's not full
+	// if its oldest element is first.
+	b.linearize()
+	b.maxLen = n
+
+	drop := len(b.buf) - n
+	if drop <= 0 {
+		return
+	}
+
+	var kept []T
+	switch policy {
+	case DropOldest:
+		b.overwrite(drop, nil)
+		kept = b.buf[drop:]
+		b.readPos = max(b.readPos-drop, 0)
+	case DropNewest:
+		kept = b.buf[:n]
+		b.readPos = min(b.readPos, n)
+		b.written -= int64(drop)
+		b.truncated()
+	default:
+		panic("ringbuf.Buffer.SetMaxLen: invalid policy")
+	}
+
+	b.buf = nil
+	if n > 0 {
+		b.buf = make([]T, n)
+		copy(b.buf, kept)
+	}
+}
+
+// Shrink releases the capacity of the buffer that is not in use
+// by moving its elements to a new backing array of the exact size.
+// The buffer grows again as needed, up to its maximum length.
+func (b *Buffer[T]) Shrink() {
+	if cap(b.buf) == len(b.buf) {
+		return
+	}
+	if len(b.buf) == 0 {
+		b.buf = nil
+		b.writePos = 0
+		return
+	}
+	s := make([]T, 0, len(b.buf))
+	for seg := range b.seq(0) {
+		s = append(s, seg...)
+	}
+	b.buf = s
+	b.writePos = 0
+}
+
+// linearize rotates the buffer in place so that its oldest element is first.
+func (b *Buffer[T]) linearize() {
+	if b.writePos != 0 {
+		slices.Reverse(b.buf[:b.writePos])
+		slices.Reverse(b.buf[b.writePos:])
+		slices.Reverse(b.buf)
+		b.writePos = 0
+	}
+}
 
 func (b *Buffer[T]) Reset() {
 	b.buf = b.buf[:0]
 	b.writePos = 0
 	b.readPos = 0
+	b.overwritten = 0
+}
+
+// Overwritten returns the number of elements
+// that writes have dropped from the buffer to make room for new ones,
+// including elements of a write that's longer than the buffer,
+// and that [Buffer.SetMaxLen] has dropped with [DropOldest],
+// since the buffer was created or reset.
+func (b *Buffer[T]) Overwritten() int64 {
+	return b.overwritten
+}
+
+// OnOverwrite sets f to be called with the elements
+// that writes or [Buffer.SetMaxLen] with [DropOldest]
+// drop from the buffer, oldest first,
+// right before they're overwritten.
+// The elements may be passed in more than one call,
+// and dropped aliases the buffer, so it must not be retained.
+// A nil f removes the hook.
+func (b *Buffer[T]) OnOverwrite(f func(dropped []T)) {
+	b.onOverwrite = f
+}
+
+// SetTruncationMarker sets f to be called by [Buffer.Bytes]
+// (and [ByteBuffer.String]) when elements have been overwritten,
+// to return a marker that's prepended to the data,
+// for example:
+//
+//	b.SetTruncationMarker(func(n int64) []byte {
+//		return fmt.Appendf(nil, "[%d bytes truncated]\n", n)
+//	})
+//
+// A nil f removes the marker.
+func (b *Buffer[T]) SetTruncationMarker(f func(overwritten int64) []T) {
+	b.marker = f
+}
+
+// Written returns the total number of elements written to the buffer,
+// which is the stream offset of the next element to be written.
+// Elements dropped from the end of the buffer,
+// like by [Buffer.Truncate], are not counted,
+// so their offsets are taken by the elements written after them.
+// [Reader] accounts for this.
+func (b *Buffer[T]) Written() int64 {
+	return b.written
+}
+
+// Start returns the stream offset of the oldest element the buffer retains.
+func (b *Buffer[T]) Start() int64 {
+	return b.written - int64(len(b.buf))
 }
 
 // Len returns the number of unread elements of the buffer;
-// r.Len() == len(r.Bytes())
+// r.Len() == len(r.Bytes()) unless there's a truncation marker.
 func (b *Buffer[T]) Len() int {
 	return len(b.buf) - b.readPos
 }
@@ -0,0 +1,289 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package ringbuf_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/layer8co/toolbox/container/ringbuf"
+)
+
+// model is a simple slice-backed model of [ringbuf.ByteBuffer].
+type model struct {
+	maxLen  int
+	buf     []byte // The retained data, oldest first.
+	start   int64  // The stream offset of buf[0].
+	readPos int
+	dropped []byte // The data overwritten by writes and dropped by SetMaxLen(DropOldest).
+}
+
+func (m *model) write(p []byte) {
+	m.buf = append(m.buf, p...)
+	if drop := len(m.buf) - m.maxLen; drop > 0 {
+		m.dropped = append(m.dropped, m.buf[:drop]...)
+		m.buf = append([]byte(nil), m.buf[drop:]...)
+		m.start += int64(drop)
+		m.readPos = max(m.readPos-drop, 0)
+	}
+}
+
+func (m *model) written() int64 {
+	return m.start + int64(len(m.buf))
+}
+
+func (m *model) setMaxLen(n int, policy ringbuf.DropPolicy) {
+	m.maxLen = n
+	drop := len(m.buf) - n
+	if drop <= 0 {
+		return
+	}
+	if policy == ringbuf.DropOldest {
+		m.dropped = append(m.dropped, m.buf[:drop]...)
+		m.buf = m.buf[drop:]
+		m.start += int64(drop)
+		m.readPos = max(m.readPos-drop, 0)
+	} else {
+		m.buf = m.buf[:n]
+		m.readPos = min(m.readPos, n)
+	}
+}
+
+func (m *model) unread() []byte {
+	return m.buf[m.readPos:]
+}
+
+func TestBufferModel(t *testing.T) {
+
+	alphabet := []rune("abcdefgé€😀")
+
+	for seed := range uint64(200) {
+
+		r := rand.New(rand.NewPCG(seed, seed))
+
+		maxLen := r.IntN(24)
+		b := ringbuf.NewByteBuffer(maxLen, r.IntN(maxLen+1))
+		m := &model{maxLen: maxLen}
+
+		var dropped []byte
+		b.OnOverwrite(func(p []byte) {
+			dropped = append(dropped, p...)
+		})
+
+		fail := func(op int, format string, args ...any) {
+			t.Helper()
+			t.Fatalf("seed %d, op %d: "+format, append([]any{seed, op}, args...)...)
+		}
+
+		for i := range 300 {
+
+			n := r.IntN(m.maxLen + 3)
+
+			switch r.IntN(12) {
+
+			case 0, 1:
+				var p []byte
+				for range r.IntN(2*m.maxLen + 2) {
+					p = utf8.AppendRune(p, alphabet[r.IntN(len(alphabet))])
+				}
+				b.Write(p)
+				m.write(p)
+
+			case 2:
+				got := make([]byte, n)
+				k, err := b.Read(got)
+				eof := len(m.unread()) == 0
+				want := m.unread()[:min(n, len(m.unread()))]
+				m.readPos += len(want)
+				if string(got[:k]) != string(want) {
+					fail(i, "Read(%d): want %q, got %q", n, want, got[:k])
+				}
+				if (err == io.EOF) != eof {
+					fail(i, "Read(%d): unexpected error %v", n, err)
+				}
+
+			case 3:
+				got, err := b.Peek(n)
+				want := m.unread()[:min(n, len(m.unread()))]
+				if string(got) != string(want) || (err == io.EOF) != (len(want) < n) {
+					fail(i, "Peek(%d): want %q, got %q, %v", n, want, got, err)
+				}
+
+			case 4:
+				k, err := b.Discard(n)
+				want := min(n, len(m.unread()))
+				m.readPos += want
+				if k != want || (err == io.EOF) != (want < n) {
+					fail(i, "Discard(%d): want %d, got %d, %v", n, want, k, err)
+				}
+
+			case 5:
+				whence := r.IntN(3)
+				offset := int64(r.IntN(2*m.maxLen+3) - m.maxLen - 1)
+				if whence == io.SeekStart {
+					offset += m.start
+				}
+				got, err := b.Seek(offset, whence)
+				want := offset + []int64{0, m.start + int64(m.readPos), m.written()}[whence]
+				switch {
+				case want < 0:
+					if !errors.Is(err, ringbuf.ErrNegativeOffset) {
+						fail(i, "Seek(%d, %d): want ErrNegativeOffset, got %v", offset, whence, err)
+					}
+				case want < m.start:
+					if !errors.Is(err, ringbuf.ErrOverwritten) {
+						fail(i, "Seek(%d, %d): want ErrOverwritten, got %v", offset, whence, err)
+					}
+				case want > m.written():
+					if !errors.Is(err, ringbuf.ErrOutOfRange) {
+						fail(i, "Seek(%d, %d): want ErrOutOfRange, got %v", offset, whence, err)
+					}
+				default:
+					m.readPos = int(want - m.start)
+					if got != want || err != nil {
+						fail(i, "Seek(%d, %d): want %d, got %d, %v", offset, whence, want, got, err)
+					}
+				}
+
+			case 6:
+				err := b.UnreadByte()
+				if m.readPos == 0 {
+					if !errors.Is(err, ringbuf.ErrUnread) {
+						fail(i, "UnreadByte: want ErrUnread, got %v", err)
+					}
+				} else {
+					m.readPos--
+				}
+
+			case 7:
+				err := b.UnreadRune()
+				if m.readPos == 0 {
+					if !errors.Is(err, ringbuf.ErrUnread) {
+						fail(i, "UnreadRune: want ErrUnread, got %v", err)
+					}
+				} else {
+					_, size := utf8.DecodeLastRune(m.buf[max(m.readPos-utf8.UTFMax, 0):m.readPos])
+					m.readPos -= size
+				}
+
+			case 8:
+				ru, size, err := b.ReadRune()
+				wantRu, wantSize := utf8.DecodeRune(m.unread())
+				if len(m.unread()) == 0 {
+					wantRu, wantSize = 0, 0
+				}
+				m.readPos += wantSize
+				if ru != wantRu || size != wantSize || (err == io.EOF) != (wantSize == 0) {
+					fail(i, "ReadRune: want %q, %d, got %q, %d, %v", wantRu, wantSize, ru, size, err)
+				}
+
+			case 9:
+				k := r.IntN(len(m.unread()) + 1)
+				b.Truncate(k)
+				m.buf = m.buf[:m.readPos+k]
+
+			case 10:
+				policy := ringbuf.DropPolicy(r.IntN(2))
+				dropped := len(m.buf) > n
+				b.SetMaxLen(n, policy)
+				m.setMaxLen(n, policy)
+				if b.MaxLen() != n {
+					fail(i, "SetMaxLen(%d): got max len %d", n, b.MaxLen())
+				}
+				if dropped && b.Cap() != n {
+					fail(i, "SetMaxLen(%d): backing array was not reclaimed, cap %d", n, b.Cap())
+				}
+
+			case 11:
+				b.Shrink()
+				if b.Cap() != len(m.buf) {
+					fail(i, "Shrink: want cap %d, got %d", len(m.buf), b.Cap())
+				}
+			}
+
+			if err := m.check(b); err != nil {
+				fail(i, "%v", err)
+			}
+			if string(dropped) != string(m.dropped) {
+				fail(i, "OnOverwrite: want %q, got %q", m.dropped, dropped)
+			}
+			if m.start > 0 {
+				if _, err := b.ReadAt(make([]byte, 1), m.start-1); !errors.Is(err, ringbuf.ErrOverwritten) {
+					fail(i, "ReadAt(%d): want ErrOverwritten, got %v", m.start-1, err)
+				}
+			}
+		}
+	}
+}
+
+func TestTruncationMarker(t *testing.T) {
+
+	b := ringbuf.NewByteBuffer(8)
+	b.SetTruncationMarker(func(n int64) []byte {
+		return fmt.Appendf(nil, "[%d bytes truncated] ", n)
+	})
+
+	b.WriteString("hello")
+	diff(t, "not truncated", "hello", b.String())
+
+	b.WriteString(" world")
+	diff(t, "String", "[3 bytes truncated] lo world", b.String())
+	diff(t, "Bytes", "[3 bytes truncated] lo world", b.Bytes())
+	assertEqual(t, "Len", 8, b.Len())
+
+	b.Reset()
+	b.WriteString("hi")
+	diff(t, "after Reset", "hi", b.String())
+}
+
+func TestReadFromOverwrite(t *testing.T) {
+
+	data := strings.Repeat("0123456789", 10000)
+
+	for _, hook := range []bool{false, true} {
+
+		b := ringbuf.NewByteBuffer(1000)
+		var dropped []byte
+		if hook {
+			b.OnOverwrite(func(p []byte) {
+				dropped = append(dropped, p...)
+			})
+		}
+
+		b.ReadFrom(strings.NewReader(data))
+		diff(t, "Bytes", data[len(data)-1000:], b.Bytes())
+		assertEqual(t, "Overwritten", int64(len(data)-1000), b.Overwritten())
+		if hook {
+			diff(t, "dropped", data[:len(data)-1000], dropped)
+		}
+	}
+}
+
+func TestReadPositionOverwrite(t *testing.T) {
+
+	// Overwriting writes keep the read position at the same unread element.
+	b := ringbuf.NewByteBuffer(4)
+	b.WriteString("abcd")
+	b.Next(2)
+	b.WriteString("e")
+	diff(t, "Bytes", "cde", b.Bytes())
+	if off, err := b.Seek(0, io.SeekCurrent); off != 2 || err != nil {
+		t.Errorf("Seek(0, io.SeekCurrent): want 2, nil, got %d, %v", off, err)
+	}
+
+	// Unless it's overwritten.
+	b.WriteString("fgh")
+	diff(t, "Bytes", "efgh", b.Bytes())
+	assertEqual(t, "Start", 4, b.Start())
+
+	// Same for ReadFrom.
+	b.Next(1)
+	b.ReadFrom(strings.NewReader("ij"))
+	diff(t, "ReadFrom", "ghij", b.Bytes())
+}
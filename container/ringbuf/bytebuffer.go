@@ -33,7 +33,7 @@ func (b *ByteBuffer) WriteRune(ru rune) (int, error) {
 }
 
 func (b *ByteBuffer) ReadRune() (r rune, size int, err error) {
-	n, err := b.ReadAt(b.runeBuf[:], int64(b.readPos))
+	n, err := b.readAt(b.runeBuf[:], b.readPos)
 	if err != nil {
 		return 0, 0, err
 	}
@@ -42,54 +42,85 @@ func (b *ByteBuffer) ReadRune() (r rune, size int, err error) {
 	return r, size, nil
 }
 
+// UnreadRune unreads the rune before the read position.
+// Unlike [bytes.Buffer.UnreadRune],
+// it can be called repeatedly as long as the buffer retains the data.
+func (b *ByteBuffer) UnreadRune() error {
+	if b.readPos == 0 {
+		return ErrUnread
+	}
+	start := max(b.readPos-utf8.UTFMax, 0)
+	n, _ := b.readAt(b.runeBuf[:b.readPos-start], start)
+	_, size := utf8.DecodeLastRune(b.runeBuf[:n])
+	b.readPos -= size
+	return nil
+}
+
+// WriteTo writes the unread bytes of the buffer to w.
+// If w is an [*os.File] or a [net.Conn],
+// both segments of the ring are written with a single vectored write.
 func (b *ByteBuffer) WriteTo(w io.Writer) (n int64, err error) {
-	for s := range b.BytesSeq() {
-		m, err := w.Write(s)
-		n += int64(m)
-		b.readPos += m
-		if err != nil {
-			return n, err
-		}
-		if m <= len(s) {
-			return n, io.ErrShortWrite
-		}
+	var segs [2][]byte
+	s := segs[:0]
+	for seg := range b.BytesSeq() {
+		s = append(s, seg)
 	}
-	return n, nil
+	n, err = writeSegs(w, s)
+	b.readPos += int(n)
+	return n, err
 }
 
+// ReadFrom reads from r until EOF,
+// overwriting old data once the buffer is full.
+// If r is an [*os.File] or a [*net.TCPConn],
+// both free segments of the ring are filled with a single vectored read.
 func (b *ByteBuffer) ReadFrom(r io.Reader) (n int64, err error) {
 
-	// TODO:
-	// If small reads (caused by a small ringbuf)
-	// turn out to be too slow even with a fast `r io.Reader`,
-	// somehow read to a small 1kb (or however big) buffer.
+	const minRead = 512
 
-	if b.maxLen == 0 {
-		return 0, nil
-	}
+	var segs [2][]byte
+	var scratch []byte
+	for {
 
-	const minRead = 512
+		s := segs[:0]
+		full := len(b.buf) == b.maxLen
 
-	for b.rem() > 0 {
-		b.Grow(minRead)
-		m, err := r.Read(b.buf[len(b.buf):cap(b.buf)])
-		b.buf = b.buf[:len(b.buf)+m]
-		n += int64(m)
-		if err == io.EOF {
-			return n, nil
+		// The overwrite hook must see the data before it's overwritten,
+		// and a buffer with no room still accounts for the data.
+		if full && (b.onOverwrite != nil || b.maxLen == 0) {
+			if scratch == nil {
+				scratch = make([]byte, max(min(b.maxLen, 32<<10), minRead))
+			}
+			m, err := r.Read(scratch)
+			b.Write(scratch[:m])
+			n += int64(m)
+			if err == io.EOF {
+				return n, nil
+			}
+			if err != nil {
+				return n, err
+			}
+			continue
 		}
-		if err != nil {
-			return n, err
+
+		if full {
+			s = append(s, b.buf[b.writePos:], b.buf[:b.writePos])
+		} else {
+			b.Grow(minRead)
+			s = append(s, b.buf[len(b.buf):min(cap(b.buf), b.maxLen)])
 		}
-	}
 
-	for {
-		m, err := r.Read(b.buf[b.writePos:])
-		n += int64(m)
-		b.writePos += m
-		if b.writePos == b.maxLen {
-			b.writePos = 0
+		m, err := readSegs(r, s)
+		if full {
+			b.writePos = (b.writePos + m) % b.maxLen
+			b.readPos = max(b.readPos-m, 0)
+			b.overwritten += int64(m)
+		} else {
+			b.buf = b.buf[:len(b.buf)+m]
 		}
+		b.written += int64(m)
+		n += int64(m)
+
 		if err == io.EOF {
 			return n, nil
 		}
@@ -105,10 +136,9 @@ func (b *ByteBuffer) String() string {
 	if b == nil {
 		return "<nil>"
 	}
-	if b.Len() == 0 {
+	s := b.Bytes()
+	if len(s) == 0 {
 		return ""
 	}
-	s := make([]byte, b.Len())
-	b.ReadAt(s, int64(b.readPos))
 	return unsafe.String(&s[0], len(s))
 }
@@ -0,0 +1,424 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package ringbuf
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"iter"
+	"os"
+	"time"
+)
+
+var (
+	ErrCorrupt      = errors.New("corrupt ring buffer file")
+	ErrMaxLenChange = errors.New("max length of ring buffer file cannot be changed")
+)
+
+// SyncPolicy determines when a [FileBuffer] flushes its data to disk.
+type SyncPolicy uint8
+
+const (
+	// SyncNever leaves flushing to the operating system,
+	// which is enough for the data to survive crashes of the process,
+	// but not of the system.
+	SyncNever SyncPolicy = iota
+
+	// SyncAlways flushes after every write.
+	SyncAlways
+
+	// SyncInterval flushes on writes
+	// when the last flush is older than [FileBufferOptions.Interval].
+	SyncInterval
+)
+
+type FileBufferOptions struct {
+	Sync     SyncPolicy
+	Interval time.Duration
+}
+
+// FileBuffer is a ring buffer of bytes backed by a memory-mapped file,
+// with the same reading API as [ByteBuffer].
+// Its contents survive restarts of the process,
+// which makes it suitable for flight-recorder logs.
+//
+// The file starts with a header that stores the state of the buffer,
+// followed by the data.
+// The header is written in two alternating slots with checksums,
+// so that a torn header write leaves the previous one intact.
+// Before a write overwrites old data,
+// a header that no longer includes that data is written,
+// so a crash while writing loses the data being written
+// and the old data it was overwriting, but nothing else.
+//
+// The read position isn't persisted;
+// a reopened buffer is read from its oldest byte.
+//
+// It's only supported on Linux for now;
+// elsewhere, [OpenFileBuffer] returns [errors.ErrUnsupported].
+type FileBuffer struct {
+	b    ByteBuffer
+	f    *os.File
+	mem  []byte
+	opts FileBufferOptions
+
+	seq      uint64 // Sequence number of the last header written.
+	wraps    uint64
+	start    int64 // Stream offset of the oldest byte the header includes.
+	lastSync time.Time
+}
+
+const (
+	fileMagic      = "ringbuf\x00"
+	fileVersion    = 1
+	fileSlotSize   = 64
+	fileHeaderSize = 2 * fileSlotSize
+)
+
+// fileHeader is the layout of a header slot,
+// which is encoded in little-endian order.
+type fileHeader struct {
+	magic    [8]byte
+	version  uint32
+	crc      uint32 // CRC-32 (IEEE) of the slot with a zero crc.
+	seq      uint64
+	maxLen   uint64
+	len      uint64
+	writePos uint64 // The data is the len bytes before writePos, wrapping around.
+	wraps    uint64
+	written  uint64
+}
+
+// OpenFileBuffer opens the ring buffer file at path,
+// creating it with room for maxLen bytes if it doesn't exist or is empty.
+//
+// A maxLen that's not positive opens an existing file with its max length.
+// Otherwise, it must match the max length of an existing file,
+// or [ErrMaxLenChange] is returned.
+// [ErrCorrupt] is returned if the file isn't a valid ring buffer file.
+//
+// opts may be nil, in which case the buffer uses [SyncNever].
+// The file is locked while it's open.
+func OpenFileBuffer(path string, maxLen int, opts *FileBufferOptions) (fb *FileBuffer, err error) {
+
+	if !mmapSupported {
+		return nil, fmt.Errorf("open %s: %w", path, errors.ErrUnsupported)
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0666)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+		}
+	}()
+
+	if err := lockFile(f); err != nil {
+		return nil, fmt.Errorf("lock %s: %w", path, err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	size := info.Size()
+	create := size == 0
+	switch {
+	case create && maxLen <= 0:
+		return nil, fmt.Errorf("create %s: maxLen <= 0", path)
+	case create:
+		size = fileHeaderSize + int64(maxLen)
+		if err := f.Truncate(size); err != nil {
+			return nil, err
+		}
+	case size < fileHeaderSize:
+		return nil, fmt.Errorf("%s: %w: file too small", path, ErrCorrupt)
+	case maxLen > 0 && size != fileHeaderSize+int64(maxLen):
+		return nil, fmt.Errorf("%s: %w: want %d, got %d", path, ErrMaxLenChange, maxLen, size-fileHeaderSize)
+	}
+
+	mem, err := mmapFile(f, int(size))
+	if err != nil {
+		return nil, fmt.Errorf("mmap %s: %w", path, err)
+	}
+
+	fb = &FileBuffer{
+		f:   f,
+		mem: mem,
+	}
+	if opts != nil {
+		fb.opts = *opts
+	}
+	fb.b.maxLen = int(size - fileHeaderSize)
+	fb.b.buf = mem[fileHeaderSize:fileHeaderSize:size]
+
+	if create {
+		fb.commit()
+		err = fb.sync()
+	} else {
+		err = fb.load()
+	}
+	if err != nil {
+		munmapFile(mem)
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return fb, nil
+}
+
+// load restores the state of the buffer from the newest valid header slot.
+func (fb *FileBuffer) load() error {
+
+	var h fileHeader
+	ok := false
+	for i := range 2 {
+		s, err := decodeFileHeader(fb.mem[i*fileSlotSize : (i+1)*fileSlotSize])
+		if err == nil && (!ok || s.seq > h.seq) {
+			h, ok = s, true
+		}
+	}
+	if !ok {
+		return fmt.Errorf("%w: no valid header", ErrCorrupt)
+	}
+
+	maxLen := uint64(fb.b.maxLen)
+	switch {
+	case h.maxLen != maxLen:
+		return fmt.Errorf("%w: header has max length %d, file has %d", ErrCorrupt, h.maxLen, maxLen)
+	case h.len > maxLen || h.len > h.written:
+		return fmt.Errorf("%w: length %d out of range", ErrCorrupt, h.len)
+	case h.writePos >= max(maxLen, 1):
+		return fmt.Errorf("%w: write position %d out of range", ErrCorrupt, h.writePos)
+	}
+
+	fb.seq = h.seq
+	fb.wraps = h.wraps
+	fb.b.written = int64(h.written)
+	fb.start = fb.b.written - int64(h.len)
+
+	// Data that doesn't start at the beginning of the ring
+	// is what a crash while overwriting old data leaves,
+	// which is loaded as a full buffer with the bytes before it skipped.
+	if h.len == 0 || (h.writePos+maxLen-h.len)%maxLen == 0 {
+		fb.b.buf = fb.b.buf[:h.len]
+		fb.b.writePos = 0
+	} else {
+		fb.b.buf = fb.b.buf[:maxLen]
+		fb.b.writePos = int(h.writePos)
+		fb.b.readPos = int(maxLen - h.len)
+	}
+	return nil
+}
+
+func decodeFileHeader(b []byte) (h fileHeader, err error) {
+
+	copy(h.magic[:], b)
+	h.version = binary.LittleEndian.Uint32(b[8:])
+	h.crc = binary.LittleEndian.Uint32(b[12:])
+	h.seq = binary.LittleEndian.Uint64(b[16:])
+	h.maxLen = binary.LittleEndian.Uint64(b[24:])
+	h.len = binary.LittleEndian.Uint64(b[32:])
+	h.writePos = binary.LittleEndian.Uint64(b[40:])
+	h.wraps = binary.LittleEndian.Uint64(b[48:])
+	h.written = binary.LittleEndian.Uint64(b[56:])
+
+	switch {
+	case string(h.magic[:]) != fileMagic:
+		return h, fmt.Errorf("%w: bad magic", ErrCorrupt)
+	case h.version != fileVersion:
+		return h, fmt.Errorf("%w: unsupported version %d", ErrCorrupt, h.version)
+	case h.crc != h.checksum():
+		return h, fmt.Errorf("%w: bad header checksum", ErrCorrupt)
+	}
+	return h, nil
+}
+
+func (h *fileHeader) encode(b []byte) {
+	copy(b, h.magic[:])
+	binary.LittleEndian.PutUint32(b[8:], h.version)
+	binary.LittleEndian.PutUint32(b[12:], h.crc)
+	binary.LittleEndian.PutUint64(b[16:], h.seq)
+	binary.LittleEndian.PutUint64(b[24:], h.maxLen)
+	binary.LittleEndian.PutUint64(b[32:], h.len)
+	binary.LittleEndian.PutUint64(b[40:], h.writePos)
+	binary.LittleEndian.PutUint64(b[48:], h.wraps)
+	binary.LittleEndian.PutUint64(b[56:], h.written)
+}
+
+func (h *fileHeader) checksum() uint32 {
+	var b [fileSlotSize]byte
+	c := *h
+	c.crc = 0
+	c.encode(b[:])
+	return crc32.ChecksumIEEE(b[:])
+}
+
+// commit writes the state of the buffer to the next header slot.
+func (fb *FileBuffer) commit() {
+	fb.seq++
+	h := fileHeader{
+		version: fileVersion,
+		seq:     fb.seq,
+		maxLen:  uint64(fb.b.maxLen),
+		len:     uint64(fb.b.written - fb.Start()),
+		wraps:   fb.wraps,
+		written: uint64(fb.b.written),
+	}
+	if fb.b.maxLen > 0 {
+		h.writePos = uint64((fb.b.writePos + len(fb.b.buf)) % fb.b.maxLen)
+	}
+	copy(h.magic[:], fileMagic)
+	h.crc = h.checksum()
+	i := int(fb.seq % 2)
+	h.encode(fb.mem[i*fileSlotSize : (i+1)*fileSlotSize])
+}
+
+func (fb *FileBuffer) Write(p []byte) (int, error) {
+
+	// Drop the old data about to be overwritten from the header first,
+	// so that a crash can't leave it partially overwritten.
+	size := int(fb.b.written - fb.Start())
+	if drop := min(size+len(p)-fb.b.maxLen, size); drop > 0 {
+		fb.start = fb.Start() + int64(drop)
+		if err := fb.commitAndSync(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, _ := fb.b.Write(p)
+	if fb.b.maxLen > 0 {
+		fb.wraps = uint64(fb.b.written) / uint64(fb.b.maxLen)
+	}
+
+	return n, fb.commitAndSync()
+}
+
+func (fb *FileBuffer) WriteString(s string) (int, error) {
+	return fb.Write([]byte(s))
+}
+
+func (fb *FileBuffer) commitAndSync() error {
+	switch fb.opts.Sync {
+	case SyncAlways:
+	case SyncInterval:
+		if time.Since(fb.lastSync) < fb.opts.Interval {
+			fb.commit()
+			return nil
+		}
+	default:
+		fb.commit()
+		return nil
+	}
+
+	// Flush the data before the header that refers to it.
+	if err := msyncFile(fb.mem); err != nil {
+		return err
+	}
+	fb.commit()
+	return fb.sync()
+}
+
+// Sync flushes the contents of the buffer to disk.
+func (fb *FileBuffer) Sync() error {
+	return fb.sync()
+}
+
+func (fb *FileBuffer) sync() error {
+	fb.lastSync = time.Now()
+	return msyncFile(fb.mem)
+}
+
+// Close flushes the buffer to disk and closes its file.
+// The buffer must not be used afterwards.
+func (fb *FileBuffer) Close() error {
+	err := fb.sync()
+	fb.b.buf = nil
+	if e := munmapFile(fb.mem); err == nil {
+		err = e
+	}
+	fb.mem = nil
+	if e := fb.f.Close(); err == nil {
+		err = e
+	}
+	return err
+}
+
+func (fb *FileBuffer) Read(p []byte) (int, error) {
+	return fb.b.Read(p)
+}
+
+func (fb *FileBuffer) ReadByte() (byte, error) {
+	return fb.b.ReadByte()
+}
+
+func (fb *FileBuffer) ReadRune() (r rune, size int, err error) {
+	return fb.b.ReadRune()
+}
+
+// ReadAt is like [Buffer.ReadAt].
+// Stream offsets persist across reopens of the buffer.
+func (fb *FileBuffer) ReadAt(p []byte, offset int64) (int, error) {
+	if offset >= 0 && offset < fb.Start() {
+		return 0, ErrOverwritten
+	}
+	return fb.b.ReadAt(p, offset)
+}
+
+func (fb *FileBuffer) WriteTo(w io.Writer) (int64, error) {
+	return fb.b.WriteTo(w)
+}
+
+// Bytes returns a copy of the unread bytes of the buffer.
+func (fb *FileBuffer) Bytes() []byte {
+	return fb.b.Bytes()
+}
+
+// BytesSeq returns an iterator over the unread bytes of the buffer.
+// The returned slices alias the memory-mapped file
+// at least until the next buffer modification.
+func (fb *FileBuffer) BytesSeq() iter.Seq[[]byte] {
+	return fb.b.BytesSeq()
+}
+
+func (fb *FileBuffer) String() string {
+	return fb.b.String()
+}
+
+// Len returns the number of unread bytes of the buffer.
+func (fb *FileBuffer) Len() int {
+	return fb.b.Len()
+}
+
+func (fb *FileBuffer) MaxLen() int {
+	return fb.b.maxLen
+}
+
+// Written returns the total number of bytes written to the buffer
+// since its file was created.
+func (fb *FileBuffer) Written() int64 {
+	return fb.b.written
+}
+
+// Start returns the stream offset of the oldest byte the buffer retains.
+func (fb *FileBuffer) Start() int64 {
+	return max(fb.b.Start(), fb.start)
+}
+
+// Wraps returns the number of times the buffer has been filled
+// since its file was created.
+func (fb *FileBuffer) Wraps() uint64 {
+	return fb.wraps
+}
+
+// Reset discards the contents of the buffer.
+func (fb *FileBuffer) Reset() error {
+	fb.b.Reset()
+	return fb.commitAndSync()
+}
@@ -0,0 +1,29 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !linux
+
+package ringbuf
+
+import (
+	"errors"
+	"os"
+)
+
+const mmapSupported = false
+
+func mmapFile(f *os.File, size int) ([]byte, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func munmapFile(b []byte) error {
+	return errors.ErrUnsupported
+}
+
+func msyncFile(b []byte) error {
+	return errors.ErrUnsupported
+}
+
+func lockFile(f *os.File) error {
+	return nil
+}
@@ -0,0 +1,160 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux
+
+package ringbuf_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/layer8co/toolbox/container/ringbuf"
+)
+
+func openFileBuffer(t *testing.T, path string, maxLen int, opts *ringbuf.FileBufferOptions) *ringbuf.FileBuffer {
+	t.Helper()
+	b, err := ringbuf.OpenFileBuffer(path, maxLen, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestFileBuffer(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "buf")
+
+	b := openFileBuffer(t, path, 8, &ringbuf.FileBufferOptions{Sync: ringbuf.SyncAlways})
+	b.WriteString("hello ")
+	b.WriteString("world")
+	diff(t, "Bytes", "lo world", b.Bytes())
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	b = openFileBuffer(t, path, 0, nil)
+	diff(t, "reopened BytesSeq", "lo world", readBytesSeq(b.BytesSeq()))
+	diff(t, "reopened ReadAt", "lo world", readReaderAt(b))
+	assertEqual(t, "Written", 11, b.Written())
+	assertEqual(t, "Wraps", 1, b.Wraps())
+
+	b.WriteString("!")
+	buf := make([]byte, 3)
+	b.Read(buf)
+	diff(t, "Read", "o w", buf)
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	b = openFileBuffer(t, path, 8, nil)
+	diff(t, "reopened again", "o world!", b.String())
+	b.Reset()
+	b.Close()
+
+	b = openFileBuffer(t, path, 8, nil)
+	diff(t, "reopened after reset", "", b.String())
+	b.Close()
+}
+
+func TestFileBufferErrors(t *testing.T) {
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "buf")
+
+	b := openFileBuffer(t, path, 8, nil)
+	if _, err := ringbuf.OpenFileBuffer(path, 8, nil); err == nil {
+		t.Error("opened a file twice")
+	}
+	b.WriteString("ab")
+	b.WriteString("cd")
+	b.Close()
+
+	if _, err := ringbuf.OpenFileBuffer(path, 16, nil); !errors.Is(err, ringbuf.ErrMaxLenChange) {
+		t.Errorf("open with a different max length: want ErrMaxLenChange, got %v", err)
+	}
+	if _, err := ringbuf.OpenFileBuffer(filepath.Join(dir, "new"), 0, nil); err == nil {
+		t.Error("created a file without a max length")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A torn write of the newest header slot falls back to the previous one.
+	// The slots are 64 bytes long, and the newest one has an odd sequence number.
+	torn := append([]byte(nil), data...)
+	torn[64+40]++
+	os.WriteFile(path, torn, 0666)
+	b = openFileBuffer(t, path, 8, nil)
+	diff(t, "torn header", "ab", b.String())
+	b.Close()
+
+	// Corruption of both slots is detected.
+	for _, off := range [][]int{{0, 64}, {8, 64 + 8}} {
+		corrupt := append([]byte(nil), data...)
+		for _, i := range off {
+			corrupt[i]++
+		}
+		os.WriteFile(path, corrupt, 0666)
+		if _, err := ringbuf.OpenFileBuffer(path, 8, nil); !errors.Is(err, ringbuf.ErrCorrupt) {
+			t.Errorf("corrupt header at %v: want ErrCorrupt, got %v", off, err)
+		}
+	}
+
+	os.WriteFile(path, []byte("not a ring buffer"), 0666)
+	if _, err := ringbuf.OpenFileBuffer(path, 0, nil); !errors.Is(err, ringbuf.ErrCorrupt) {
+		t.Errorf("small file: want ErrCorrupt, got %v", err)
+	}
+}
+
+func TestFileBufferCrash(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "buf")
+
+	b := openFileBuffer(t, path, 8, nil)
+	b.WriteString("abcdefgh")
+	b.WriteString("ij")
+	b.WriteString("XYZ")
+	b.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A crash while writing "XYZ" leaves the header written before it,
+	// which no longer includes the data "XYZ" overwrote.
+	// Simulate it by tearing the newest header slot.
+	newest := 0
+	if binary.LittleEndian.Uint64(data[64+16:]) > binary.LittleEndian.Uint64(data[16:]) {
+		newest = 64
+	}
+	data[newest+40]++
+	os.WriteFile(path, data, 0666)
+
+	b = openFileBuffer(t, path, 8, nil)
+	diff(t, "after crash", "fghij", b.String())
+	assertEqual(t, "Start", 5, b.Start())
+	assertEqual(t, "Written", 10, b.Written())
+	diff(t, "ReadAt", "fghij", readReaderAt(b))
+	if _, err := b.ReadAt(make([]byte, 1), 4); !errors.Is(err, ringbuf.ErrOverwritten) {
+		t.Errorf("ReadAt(4): want ErrOverwritten, got %v", err)
+	}
+
+	// Writes overwrite the skipped bytes first.
+	b.WriteString("12")
+	diff(t, "after write", "fghij12", b.String())
+	b.Close()
+
+	b = openFileBuffer(t, path, 8, nil)
+	diff(t, "reopened", "fghij12", b.String())
+	b.WriteString("345")
+	diff(t, "after wrapping", "hij12345", b.String())
+	assertEqual(t, "Start", 7, b.Start())
+	b.Close()
+}
@@ -0,0 +1,265 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package ringbuf_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"unicode/utf8"
+
+	"github.com/layer8co/toolbox/container/ringbuf"
+)
+
+// opReader decodes the operations of [FuzzByteBuffer] from its input.
+type opReader struct {
+	b []byte
+}
+
+func (r *opReader) done() bool {
+	return len(r.b) == 0
+}
+
+// int returns the next byte of the input, modulo n.
+func (r *opReader) int(n int) int {
+	if len(r.b) == 0 || n <= 0 {
+		return 0
+	}
+	v := int(r.b[0]) % n
+	r.b = r.b[1:]
+	return v
+}
+
+// bytes returns up to the next n bytes of the input.
+func (r *opReader) bytes(n int) []byte {
+	n = min(n, len(r.b))
+	b := r.b[:n]
+	r.b = r.b[n:]
+	return b
+}
+
+// errWriter accepts up to n bytes, then fails.
+type errWriter struct {
+	buf bytes.Buffer
+	n   int
+}
+
+var errWrite = errors.New("write failed")
+
+func (w *errWriter) Write(p []byte) (int, error) {
+	if len(p) > w.n {
+		m, _ := w.buf.Write(p[:w.n])
+		w.n = 0
+		return m, errWrite
+	}
+	w.n -= len(p)
+	return w.buf.Write(p)
+}
+
+// FuzzByteBuffer compares [ringbuf.ByteBuffer] against a slice-backed model
+// across random interleavings of operations.
+func FuzzByteBuffer(f *testing.F) {
+
+	f.Add(uint8(5), false, []byte("\x00\x07hello w\x01\x03\x00\x05éorld\x02\x04\x03\x02"))
+	f.Add(uint8(8), true, []byte("\x00\x10abcdefghijklmnop\x04\x02\x05\x03\x01\x06\x09abc€def\x07\x03"))
+	f.Add(uint8(3), false, []byte("\x06\x05\x00\x02ab\x05\x01\x00\x04cdef\x04\x09\x08"))
+	f.Add(uint8(16), true, []byte("\x05\x20\x09\x03\x08\x10\x01\x00\x06\x07\x02\x09\x04\x03"))
+	f.Add(uint8(0), false, []byte("\x00\x03abc\x01\x02\x05\x03"))
+
+	f.Fuzz(fuzzByteBuffer)
+}
+
+func fuzzByteBuffer(t *testing.T, maxLen uint8, hook bool, input []byte) {
+
+	b := ringbuf.NewByteBuffer(int(maxLen))
+	m := &model{maxLen: int(maxLen)}
+	r := &opReader{input}
+
+	var dropped []byte
+	if hook {
+		b.OnOverwrite(func(p []byte) {
+			dropped = append(dropped, p...)
+		})
+	}
+
+	for i := 0; !r.done(); i++ {
+
+		n := r.int(2*m.maxLen + 3)
+		var desc string
+
+		switch op := r.int(10); op {
+
+		case 0:
+			p := r.bytes(n)
+			desc = fmt.Sprintf("Write(%q)", p)
+			b.Write(p)
+			m.write(p)
+
+		case 1:
+			desc = fmt.Sprintf("Read(%d)", n)
+			got := make([]byte, n)
+			k, err := b.Read(got)
+			want := m.unread()[:min(n, len(m.unread()))]
+			eof := len(m.unread()) == 0
+			m.readPos += len(want)
+			if string(got[:k]) != string(want) || (err == io.EOF) != eof {
+				t.Fatalf("op %d: %s: want %q, got %q, %v", i, desc, want, got[:k], err)
+			}
+
+		case 2:
+			off := m.start + int64(r.int(len(m.buf)+4)) - 2
+			desc = fmt.Sprintf("ReadAt(%d, %d)", n, off)
+			got := make([]byte, n)
+			k, err := b.ReadAt(got, off)
+			switch {
+			case off < 0:
+				if !errors.Is(err, ringbuf.ErrNegativeOffset) {
+					t.Fatalf("op %d: %s: want ErrNegativeOffset, got %v", i, desc, err)
+				}
+			case off < m.start:
+				if !errors.Is(err, ringbuf.ErrOverwritten) {
+					t.Fatalf("op %d: %s: want ErrOverwritten, got %v", i, desc, err)
+				}
+			default:
+				want := m.buf[min(int(off-m.start), len(m.buf)):]
+				want = want[:min(n, len(want))]
+				if string(got[:k]) != string(want) {
+					t.Fatalf("op %d: %s: want %q, got %q", i, desc, want, got[:k])
+				}
+				// io.ReaderAt requires an error for short reads.
+				if (k < n) != (err != nil) || err != nil && err != io.EOF {
+					t.Fatalf("op %d: %s: read %d, got error %v", i, desc, k, err)
+				}
+			}
+
+		case 3:
+			desc = fmt.Sprintf("Next(%d)", n)
+			got := b.Next(n)
+			want := m.unread()[:min(n, len(m.unread()))]
+			m.readPos += len(want)
+			if string(got) != string(want) {
+				t.Fatalf("op %d: %s: want %q, got %q", i, desc, want, got)
+			}
+
+		case 4:
+			stop := r.int(3)
+			desc = fmt.Sprintf("NextSeq(%d), stopping after %d segments", n, stop)
+			var got []byte
+			segs := 0
+			for s := range b.NextSeq(n) {
+				got = append(got, s...)
+				segs++
+				if segs == stop {
+					break
+				}
+			}
+			want := m.unread()[:len(got)]
+			if stop == 0 {
+				want = m.unread()[:min(n, len(m.unread()))]
+			}
+			m.readPos += len(want)
+			if string(got) != string(want) {
+				t.Fatalf("op %d: %s: want %q, got %q", i, desc, want, got)
+			}
+
+		case 5:
+			p := r.bytes(n)
+			desc = fmt.Sprintf("ReadFrom(%q)", p)
+			var src io.Reader = bytes.NewReader(p)
+			if r.int(2) == 1 {
+				src = iotest.OneByteReader(src)
+			}
+			k, err := b.ReadFrom(src)
+			m.write(p)
+			if k != int64(len(p)) || err != nil {
+				t.Fatalf("op %d: %s: want %d, nil, got %d, %v", i, desc, len(p), k, err)
+			}
+
+		case 6:
+			desc = "WriteTo"
+			w := new(bytes.Buffer)
+			k, err := b.WriteTo(w)
+			want := m.unread()
+			m.readPos = len(m.buf)
+			if k != int64(len(want)) || err != nil || w.String() != string(want) {
+				t.Fatalf("op %d: %s: want %q, got %q, %d, %v", i, desc, want, w, k, err)
+			}
+
+		case 7:
+			desc = fmt.Sprintf("WriteTo, failing after %d bytes", n)
+			w := &errWriter{n: n}
+			k, err := b.WriteTo(w)
+			want := m.unread()[:min(n, len(m.unread()))]
+			m.readPos += len(want)
+			wantErr := len(m.unread()) > 0
+			if k != int64(len(want)) || (err == errWrite) != wantErr || w.buf.String() != string(want) {
+				t.Fatalf("op %d: %s: want %q, got %q, %d, %v", i, desc, want, &w.buf, k, err)
+			}
+
+		case 8:
+			desc = "ReadRune"
+			ru, size, err := b.ReadRune()
+			wantRu, wantSize := utf8.DecodeRune(m.unread())
+			if len(m.unread()) == 0 {
+				wantRu, wantSize = 0, 0
+			}
+			m.readPos += wantSize
+			if ru != wantRu || size != wantSize || (err == io.EOF) != (wantSize == 0) {
+				t.Fatalf("op %d: %s: want %q, %d, got %q, %d, %v", i, desc, wantRu, wantSize, ru, size, err)
+			}
+
+		case 9:
+			desc = fmt.Sprintf("Seek(%d, io.SeekStart)", m.start+int64(n%(len(m.buf)+1)))
+			off := m.start + int64(n%(len(m.buf)+1))
+			if _, err := b.Seek(off, io.SeekStart); err != nil {
+				t.Fatalf("op %d: %s: %v", i, desc, err)
+			}
+			m.readPos = int(off - m.start)
+		}
+
+		if err := m.check(b); err != nil {
+			t.Fatalf("op %d: after %s: %v", i, desc, err)
+		}
+		if hook && string(dropped) != string(m.dropped) {
+			t.Fatalf("op %d: after %s: OnOverwrite: want %q, got %q", i, desc, m.dropped, dropped)
+		}
+	}
+}
+
+// check compares the state of b with the model.
+func (m *model) check(b *ringbuf.ByteBuffer) error {
+	var errs []string
+	if got, want := string(b.Bytes()), string(m.unread()); got != want {
+		errs = append(errs, fmt.Sprintf("Bytes: want %q, got %q", want, got))
+	}
+	if got, want := b.String(), string(m.unread()); got != want {
+		errs = append(errs, fmt.Sprintf("String: want %q, got %q", want, got))
+	}
+	if got, want := string(readBytesSeq(b.BytesSeq())), string(m.unread()); got != want {
+		errs = append(errs, fmt.Sprintf("BytesSeq: want %q, got %q", want, got))
+	}
+	if got, want := string(readReaderAt(b)), string(m.buf); got != want {
+		errs = append(errs, fmt.Sprintf("ReadAt: want %q, got %q", want, got))
+	}
+	if b.Len() != len(m.unread()) {
+		errs = append(errs, fmt.Sprintf("Len: want %d, got %d", len(m.unread()), b.Len()))
+	}
+	if b.Written() != m.written() || b.Start() != m.start {
+		errs = append(errs, fmt.Sprintf("Written, Start: want %d, %d, got %d, %d", m.written(), m.start, b.Written(), b.Start()))
+	}
+	if off, _ := b.Seek(0, io.SeekCurrent); off != m.start+int64(m.readPos) {
+		errs = append(errs, fmt.Sprintf("read offset: want %d, got %d", m.start+int64(m.readPos), off))
+	}
+	if b.Overwritten() != int64(len(m.dropped)) {
+		errs = append(errs, fmt.Sprintf("Overwritten: want %d, got %d", len(m.dropped), b.Overwritten()))
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
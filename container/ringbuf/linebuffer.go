@@ -0,0 +1,225 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package ringbuf
+
+import (
+	"bytes"
+	"iter"
+	"math"
+	"unicode/utf8"
+)
+
+// LineBuffer retains the last lines written to it,
+// like the tail of a log or of the output of a process.
+//
+// It's limited by a number of lines, a number of bytes, or both,
+// and keeps whole lines when data is dropped:
+// if the oldest retained line was cut by the byte limit,
+// it's dropped as well, unless it's the only line,
+// in which case its tail is kept starting at a rune boundary.
+//
+// The last line doesn't need to end with a newline;
+// it's retained as it's being written.
+type LineBuffer struct {
+	b        ByteBuffer
+	maxLines int
+
+	start int64   // Offset of the first retained byte in the stream.
+	clean bool    // Whether start is the start of a line.
+	cut   int64   // Offset where the line at start was cut, if it's not clean.
+	nl    []int64 // Offsets of the retained newlines in the stream.
+}
+
+// NewLineBuffer returns a new line buffer
+// that retains up to maxLines lines and up to maxBytes bytes.
+// A limit that's not positive means no limit,
+// but at least one of them must be positive.
+func NewLineBuffer(maxLines, maxBytes int) *LineBuffer {
+	if maxLines <= 0 && maxBytes <= 0 {
+		panic("ringbuf.NewLineBuffer: no limit")
+	}
+	if maxBytes <= 0 {
+		maxBytes = math.MaxInt
+	}
+	l := &LineBuffer{
+		maxLines: maxLines,
+		clean:    true,
+	}
+	l.b.maxLen = maxBytes
+	return l
+}
+
+func (l *LineBuffer) Write(p []byte) (int, error) {
+
+	// Newlines before the last maxLen bytes are overwritten right away,
+	// except for the one right before them, which tells if they're clean.
+	skip := max(len(p)-l.b.maxLen-1, 0)
+	for i, c := range p[skip:] {
+		if c == '\n' {
+			l.nl = append(l.nl, l.b.written+int64(skip+i))
+		}
+	}
+
+	l.b.Write(p)
+
+	if ws := l.b.Start(); l.start < ws {
+		l.dropNewlines(ws - 1)
+		l.clean = ws == 0 || len(l.nl) > 0 && l.nl[0] == ws-1
+		l.start = ws
+		l.cut = ws
+	}
+	l.dropNewlines(l.start)
+
+	// Skip the rest of a rune that was cut,
+	// which may still be being written.
+	var c [1]byte
+	for !l.clean && l.start < min(l.b.written, l.cut+utf8.UTFMax-1) {
+		l.b.ReadAt(c[:], l.start)
+		if utf8.RuneStart(c[0]) {
+			break
+		}
+		l.start++
+	}
+
+	// Drop the cut line if there's more after it.
+	if !l.clean && len(l.nl) > 0 && l.nl[0]+1 < l.b.written {
+		l.startAfterNewline()
+	}
+
+	for l.maxLines > 0 && l.lineCount() > l.maxLines {
+		l.startAfterNewline()
+	}
+
+	l.compact()
+
+	return len(p), nil
+}
+
+func (l *LineBuffer) WriteString(s string) (int, error) {
+	return l.Write([]byte(s))
+}
+
+// Lines returns an iterator over the retained lines,
+// without their trailing newlines.
+// The last line is yielded even if it's incomplete.
+//
+// The yielded slices are copies of the retained data.
+func (l *LineBuffer) Lines() iter.Seq[[]byte] {
+	return splitLines(l.Bytes())
+}
+
+// LastLines returns up to the last n retained lines,
+// like [LineBuffer.Lines].
+func (l *LineBuffer) LastLines(n int) [][]byte {
+
+	if n <= 0 {
+		return nil
+	}
+
+	start := l.start
+	if c := l.lineCount(); c > n {
+		start = l.nl[c-n-1] + 1
+	}
+
+	var lines [][]byte
+	for line := range splitLines(l.read(start)) {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// LineCount returns the number of retained lines,
+// including an incomplete last line.
+func (l *LineBuffer) LineCount() int {
+	return l.lineCount()
+}
+
+// Bytes returns a copy of the retained data.
+func (l *LineBuffer) Bytes() []byte {
+	return l.read(l.start)
+}
+
+func (l *LineBuffer) String() string {
+	return string(l.Bytes())
+}
+
+// Len returns the number of retained bytes.
+func (l *LineBuffer) Len() int {
+	return int(l.b.written - l.start)
+}
+
+func (l *LineBuffer) Reset() {
+	l.b.Reset()
+	l.b.written = 0
+	l.start = 0
+	l.clean = true
+	l.cut = 0
+	l.nl = l.nl[:0]
+}
+
+// read returns a copy of the data from the stream offset start.
+func (l *LineBuffer) read(start int64) []byte {
+	s := make([]byte, l.b.written-start)
+	l.b.ReadAt(s, start)
+	return s
+}
+
+func (l *LineBuffer) lineCount() int {
+	end := l.start
+	if len(l.nl) > 0 {
+		end = l.nl[len(l.nl)-1] + 1
+	}
+	if l.b.written > end {
+		return len(l.nl) + 1
+	}
+	return len(l.nl)
+}
+
+func (l *LineBuffer) startAfterNewline() {
+	l.start = l.nl[0] + 1
+	l.clean = true
+	l.nl = l.nl[1:]
+}
+
+// dropNewlines drops the newlines before the stream offset off.
+func (l *LineBuffer) dropNewlines(off int64) {
+	i := 0
+	for i < len(l.nl) && l.nl[i] < off {
+		i++
+	}
+	l.nl = l.nl[i:]
+}
+
+// compact reclaims the memory of dropped data
+// when it takes up most of the buffer.
+func (l *LineBuffer) compact() {
+
+	if cap(l.nl) > 64 && len(l.nl) < cap(l.nl)/4 {
+		l.nl = append([]int64(nil), l.nl...)
+	}
+
+	// Bounded buffers reuse the memory of dropped data anyway.
+	if l.b.maxLen != math.MaxInt {
+		return
+	}
+	d := int(l.start - l.b.Start())
+	if d > 0 && d >= len(l.b.buf)/2 {
+		l.b.linearize()
+		n := copy(l.b.buf, l.b.buf[d:])
+		clear(l.b.buf[n:])
+		l.b.buf = l.b.buf[:n]
+	}
+}
+
+func splitLines(b []byte) iter.Seq[[]byte] {
+	return func(yield func([]byte) bool) {
+		for len(b) > 0 {
+			line, rest, _ := bytes.Cut(b, []byte{'\n'})
+			if !yield(line) {
+				return
+			}
+			b = rest
+		}
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package ringbuf_test
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/layer8co/toolbox/container/ringbuf"
+)
+
+func TestLineBuffer(t *testing.T) {
+
+	testCases := []struct {
+		line      int
+		maxLines  int
+		maxBytes  int
+		writes    []string
+		wantLines []string
+	}{
+		{
+			line(),
+			3, 0,
+			[]string{"a\nb\nc\n", "d\ne"},
+			[]string{"c", "d", "e"},
+		},
+		{
+			line(),
+			3, 0,
+			[]string{"a\nb\nc\nd\n"},
+			[]string{"b", "c", "d"},
+		},
+		{
+			line(),
+			0, 10,
+			[]string{"hello\n", "world\n"},
+			[]string{"world"},
+		},
+		{
+			line(),
+			0, 10,
+			[]string{"hello\nwor", "ld\n"},
+			[]string{"world"},
+		},
+		{
+			line(),
+			0, 10,
+			[]string{"hi\nlo\n", "ab"},
+			[]string{"hi", "lo", "ab"},
+		},
+		{
+			// The cut line is kept while it's the only one.
+			line(),
+			0, 6,
+			[]string{"abcdefgh"},
+			[]string{"cdefgh"},
+		},
+		{
+			line(),
+			0, 6,
+			[]string{"abcdefgh\n"},
+			[]string{"defgh"},
+		},
+		{
+			line(),
+			0, 6,
+			[]string{"abcdefgh\n", "x"},
+			[]string{"x"},
+		},
+		{
+			// The head never starts with a split rune.
+			line(),
+			0, 6,
+			[]string{"aé€😀"},
+			[]string{"😀"},
+		},
+		{
+			line(),
+			0, 7,
+			[]string{"aé€😀"},
+			[]string{"€😀"},
+		},
+		{
+			line(),
+			2, 10,
+			[]string{"a\n\n\nb"},
+			[]string{"", "b"},
+		},
+		{
+			line(),
+			1, 0,
+			[]string{"abc"},
+			[]string{"abc"},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(fmt.Sprintf("line%d", tt.line), func(t *testing.T) {
+			b := ringbuf.NewLineBuffer(tt.maxLines, tt.maxBytes)
+			for _, s := range tt.writes {
+				b.WriteString(s)
+			}
+			var got []string
+			for l := range b.Lines() {
+				got = append(got, string(l))
+			}
+			if diff := cmp.Diff(tt.wantLines, got); diff != "" {
+				t.Errorf("incorrect result (-want +got):\n%s", diff)
+			}
+			assertEqual(t, "LineCount", len(tt.wantLines), b.LineCount())
+		})
+	}
+}
+
+func TestLineBufferLastLines(t *testing.T) {
+
+	b := ringbuf.NewLineBuffer(0, 64)
+	b.WriteString("one\ntwo\nthree\nfo")
+
+	for n, want := range [][]string{
+		nil,
+		{"fo"},
+		{"three", "fo"},
+		{"two", "three", "fo"},
+		{"one", "two", "three", "fo"},
+		{"one", "two", "three", "fo"},
+	} {
+		var got []string
+		for _, l := range b.LastLines(n) {
+			got = append(got, string(l))
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("LastLines(%d): incorrect result (-want +got):\n%s", n, diff)
+		}
+	}
+
+	b.WriteString("ur\n")
+	diff(t, "LastLines(1)", "four", bytes.Join(b.LastLines(1), nil))
+}
+
+// TestLineBufferRandom checks the invariants of [ringbuf.LineBuffer]
+// against everything that was written to it.
+func TestLineBufferRandom(t *testing.T) {
+
+	alphabet := []rune("ab\n\néé€😀")
+
+	for seed := range uint64(200) {
+
+		r := rand.New(rand.NewPCG(seed, seed))
+
+		maxLines := r.IntN(5)
+		maxBytes := r.IntN(30) + 1
+		if r.IntN(4) == 0 {
+			maxLines, maxBytes = max(maxLines, 1), 0
+		}
+		b := ringbuf.NewLineBuffer(maxLines, maxBytes)
+
+		var stream []byte
+		for i := range 100 {
+
+			var p []byte
+			for range r.IntN(20) {
+				p = utf8.AppendRune(p, alphabet[r.IntN(len(alphabet))])
+			}
+			b.Write(p)
+			stream = append(stream, p...)
+
+			fail := func(format string, args ...any) {
+				t.Helper()
+				t.Fatalf("seed %d, write %d, %q: "+format, append([]any{seed, i, b.String()}, args...)...)
+			}
+
+			got := b.Bytes()
+			assertEqual(t, "Len", len(got), b.Len())
+			if !bytes.HasSuffix(stream, got) {
+				fail("not a suffix of the stream")
+			}
+			if maxBytes > 0 && len(got) > maxBytes {
+				fail("longer than %d bytes", maxBytes)
+			}
+			if maxLines > 0 && b.LineCount() > maxLines {
+				fail("more than %d lines", maxLines)
+			}
+			if len(got) > 0 && !utf8.RuneStart(got[0]) {
+				fail("split rune at the head")
+			}
+
+			// The head is at a line boundary unless the buffer holds a single cut line.
+			head := len(stream) - len(got)
+			cut := head > 0 && stream[head-1] != '\n'
+			if i := bytes.IndexByte(got, '\n'); cut && i >= 0 && i < len(got)-1 {
+				fail("cut line at the head")
+			}
+
+			// Nothing that fits is dropped.
+			if !cut && head > 0 && len(got) < len(stream) {
+				prev := bytes.LastIndexByte(stream[:head-1], '\n') + 1
+				fitsBytes := maxBytes == 0 || len(stream)-prev <= maxBytes
+				fitsLines := maxLines == 0 || b.LineCount() < maxLines
+				if fitsBytes && fitsLines {
+					fail("dropped the line %q that fits", stream[prev:head])
+				}
+			}
+
+			var lines []string
+			for l := range b.Lines() {
+				lines = append(lines, string(l))
+			}
+			if len(lines) != b.LineCount() {
+				fail("LineCount: want %d, got %d", len(lines), b.LineCount())
+			}
+			if len(lines) > 0 && strings.Join(lines, "\n") != strings.TrimSuffix(string(got), "\n") {
+				fail("Lines: got %q", lines)
+			}
+			n := r.IntN(len(lines) + 2)
+			var last []string
+			for _, l := range b.LastLines(n) {
+				last = append(last, string(l))
+			}
+			if want := lines[max(len(lines)-n, 0):]; n > 0 && !cmp.Equal(want, last, cmpopts.EquateEmpty()) {
+				fail("LastLines(%d): want %q, got %q", n, want, last)
+			}
+		}
+	}
+}
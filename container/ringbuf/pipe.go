@@ -0,0 +1,207 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package ringbuf
+
+import (
+	"context"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// PipePolicy determines what [Pipe.Write] does when the pipe is full.
+type PipePolicy uint8
+
+const (
+	// Block blocks the writer until readers make room.
+	Block PipePolicy = iota
+
+	// Overwrite never blocks the writer,
+	// overwriting the oldest unread data instead.
+	Overwrite
+)
+
+// Pipe is a bounded in-memory pipe backed by a ring buffer,
+// similar to [io.Pipe], but with its own buffer.
+// It is safe for concurrent use by multiple goroutines.
+//
+// Reads block until data is available, the write side is closed,
+// or the read deadline or context (see [Pipe.ReadContext]) expires.
+//
+// In [Overwrite] mode, writes never block,
+// which makes the pipe suitable for capturing the output of subprocesses
+// that must not be stalled by slow readers.
+type Pipe struct {
+	mu     sync.Mutex
+	cond   sync.Cond
+	b      Buffer[byte]
+	policy PipePolicy
+
+	werr    error // The error returned by reads after the write side is closed.
+	rclosed bool
+
+	rdeadline time.Time
+	rtimer    *time.Timer
+}
+
+// NewPipe returns a new pipe that buffers up to maxLen bytes.
+// It panics if maxLen is not positive.
+func NewPipe(maxLen int, policy PipePolicy) *Pipe {
+	if maxLen <= 0 {
+		panic("ringbuf.NewPipe: maxLen <= 0")
+	}
+	p := &Pipe{
+		policy: policy,
+	}
+	p.b.maxLen = maxLen
+	p.cond.L = &p.mu
+	return p
+}
+
+func (p *Pipe) Read(b []byte) (int, error) {
+	return p.ReadContext(context.Background(), b)
+}
+
+// ReadContext is like [Pipe.Read],
+// but it returns the error of ctx if it's done while blocking.
+func (p *Pipe) ReadContext(ctx context.Context, b []byte) (int, error) {
+
+	stop := context.AfterFunc(ctx, p.broadcast)
+	defer stop()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for {
+		switch {
+		case p.rclosed:
+			return 0, io.ErrClosedPipe
+		case len(b) == 0:
+			return 0, nil
+		case p.b.Len() > 0:
+			n, _ := p.b.Read(b)
+			p.cond.Broadcast()
+			return n, nil
+		case p.werr != nil:
+			return 0, p.werr
+		case ctx.Err() != nil:
+			return 0, ctx.Err()
+		case !p.rdeadline.IsZero() && !time.Now().Before(p.rdeadline):
+			return 0, os.ErrDeadlineExceeded
+		}
+		p.cond.Wait()
+	}
+}
+
+func (p *Pipe) Write(b []byte) (int, error) {
+	return p.WriteContext(context.Background(), b)
+}
+
+// WriteContext is like [Pipe.Write],
+// but it returns the error of ctx if it's done while blocking,
+// along with the number of bytes written so far.
+func (p *Pipe) WriteContext(ctx context.Context, b []byte) (n int, err error) {
+
+	stop := context.AfterFunc(ctx, p.broadcast)
+	defer stop()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for {
+
+		if p.werr != nil || p.rclosed {
+			return n, io.ErrClosedPipe
+		}
+		if len(b) == 0 {
+			return n, nil
+		}
+
+		m := len(b)
+		if p.policy == Block {
+			m = min(m, p.b.maxLen-p.b.Len())
+		}
+
+		if m > 0 {
+			p.b.Write(b[:m])
+			n += m
+			b = b[m:]
+			p.cond.Broadcast()
+			continue
+		}
+
+		if err := ctx.Err(); err != nil {
+			return n, err
+		}
+		p.cond.Wait()
+	}
+}
+
+// CloseWrite closes the write side of the pipe.
+// Reads return [io.EOF] once the buffered data is read,
+// and writes return [io.ErrClosedPipe].
+func (p *Pipe) CloseWrite() error {
+	return p.CloseWriteWithError(nil)
+}
+
+// CloseWriteWithError is like [Pipe.CloseWrite],
+// but reads return err instead of [io.EOF] if it's not nil.
+func (p *Pipe) CloseWriteWithError(err error) error {
+	if err == nil {
+		err = io.EOF
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.werr == nil {
+		p.werr = err
+	}
+	p.cond.Broadcast()
+	return nil
+}
+
+// Close closes the pipe.
+// Subsequent reads and writes return [io.ErrClosedPipe],
+// and the buffered data is discarded.
+func (p *Pipe) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.rclosed = true
+	p.b.Reset()
+	if p.rtimer != nil {
+		p.rtimer.Stop()
+	}
+	p.cond.Broadcast()
+	return nil
+}
+
+// SetReadDeadline sets the deadline for current and future reads,
+// after which blocked reads return [os.ErrDeadlineExceeded].
+// A zero value for t means reads don't time out.
+func (p *Pipe) SetReadDeadline(t time.Time) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.rdeadline = t
+	if p.rtimer != nil {
+		p.rtimer.Stop()
+		p.rtimer = nil
+	}
+	if !t.IsZero() {
+		p.rtimer = time.AfterFunc(time.Until(t), p.broadcast)
+	}
+	return nil
+}
+
+// Len returns the number of unread bytes in the pipe.
+func (p *Pipe) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.b.Len()
+}
+
+func (p *Pipe) broadcast() {
+	p.mu.Lock()
+	p.cond.Broadcast()
+	p.mu.Unlock()
+}
@@ -0,0 +1,169 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package ringbuf_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/layer8co/toolbox/container/ringbuf"
+)
+
+func TestPipeBlock(t *testing.T) {
+
+	p := ringbuf.NewPipe(7, ringbuf.Block)
+	want := strings.Repeat("the quick brown fox ", 1000)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for s := range strings.SplitSeq(want, " ") {
+			_, err := io.WriteString(p, s+" ")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+		}
+		p.CloseWrite()
+	}()
+
+	got, err := io.ReadAll(p)
+	wg.Wait()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The last write has a trailing space that the input doesn't.
+	if diff := cmp.Diff(want+" ", string(got)); diff != "" {
+		t.Errorf("incorrect result (-want +got):\n%s", diff)
+	}
+}
+
+func TestPipeOverwrite(t *testing.T) {
+
+	p := ringbuf.NewPipe(5, ringbuf.Overwrite)
+
+	// Never blocks, even though there is no reader.
+	io.WriteString(p, "abc")
+	io.WriteString(p, "defgh")
+
+	buf := make([]byte, 2)
+	p.Read(buf)
+	diff(t, "first read", "de", buf)
+
+	// Overwrites the read d and e first, then the unread f.
+	io.WriteString(p, "ijk")
+	p.CloseWrite()
+
+	got, err := io.ReadAll(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff(t, "rest", "ghijk", got)
+
+	if _, err := io.WriteString(p, "x"); err != io.ErrClosedPipe {
+		t.Errorf("write after CloseWrite: want io.ErrClosedPipe, got %v", err)
+	}
+}
+
+func TestPipeCloseWrite(t *testing.T) {
+
+	p := ringbuf.NewPipe(8, ringbuf.Block)
+	errTest := errors.New("test")
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		io.WriteString(p, "hi")
+		p.CloseWriteWithError(errTest)
+	}()
+
+	got, err := io.ReadAll(p)
+	if err != errTest {
+		t.Errorf("want errTest, got %v", err)
+	}
+	diff(t, "data", "hi", got)
+}
+
+func TestPipeClose(t *testing.T) {
+
+	p := ringbuf.NewPipe(2, ringbuf.Block)
+
+	errc := make(chan error)
+	go func() {
+		_, err := io.WriteString(p, "abc")
+		errc <- err
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	p.Close()
+
+	if err := <-errc; err != io.ErrClosedPipe {
+		t.Errorf("blocked write: want io.ErrClosedPipe, got %v", err)
+	}
+	if _, err := p.Read(make([]byte, 1)); err != io.ErrClosedPipe {
+		t.Errorf("read: want io.ErrClosedPipe, got %v", err)
+	}
+}
+
+func TestPipeDeadline(t *testing.T) {
+
+	p := ringbuf.NewPipe(8, ringbuf.Block)
+
+	p.SetReadDeadline(time.Now().Add(20 * time.Millisecond))
+	_, err := p.Read(make([]byte, 1))
+	if !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("want os.ErrDeadlineExceeded, got %v", err)
+	}
+
+	// Data is still returned after the deadline.
+	io.WriteString(p, "x")
+	if n, err := p.Read(make([]byte, 1)); n != 1 || err != nil {
+		t.Errorf("want 1, nil, got %d, %v", n, err)
+	}
+
+	p.SetReadDeadline(time.Time{})
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		io.WriteString(p, "y")
+	}()
+	if n, err := p.Read(make([]byte, 1)); n != 1 || err != nil {
+		t.Errorf("want 1, nil, got %d, %v", n, err)
+	}
+}
+
+func TestPipeContext(t *testing.T) {
+
+	p := ringbuf.NewPipe(4, ringbuf.Block)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := p.ReadContext(ctx, make([]byte, 1))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("read: want context.DeadlineExceeded, got %v", err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+	n, err := p.WriteContext(ctx, []byte("abcdef"))
+	if n != 4 || !errors.Is(err, context.Canceled) {
+		t.Errorf("write: want 4, context.Canceled, got %d, %v", n, err)
+	}
+
+	got := new(bytes.Buffer)
+	p.CloseWrite()
+	io.Copy(got, p)
+	diff(t, "data", "abcd", got.Bytes())
+}
@@ -0,0 +1,230 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package ringbuf
+
+import (
+	"math/bits"
+	"sync/atomic"
+)
+
+// cacheLinePad prevents false sharing between the fields it separates.
+type cacheLinePad [64]byte
+
+// SPSCQueue is a lock-free bounded queue
+// for a single producer goroutine and a single consumer goroutine.
+//
+// Only one goroutine may call the push methods at a time,
+// and only one goroutine may call the pop methods at a time.
+type SPSCQueue[T any] struct {
+	_    cacheLinePad
+	head atomic.Uint64 // Position of the next pop.
+	_    cacheLinePad
+	tail atomic.Uint64 // Position of the next push.
+	_    cacheLinePad
+	buf  []T
+	mask uint64
+}
+
+// NewSPSCQueue returns a new queue with the given capacity
+// rounded up to a power of two.
+// It panics if capacity is not positive.
+func NewSPSCQueue[T any](capacity int) *SPSCQueue[T] {
+	n := queueCap(capacity, "ringbuf.NewSPSCQueue")
+	return &SPSCQueue[T]{
+		buf:  make([]T, n),
+		mask: n - 1,
+	}
+}
+
+// TryPush adds v to the queue if it's not full,
+// and reports whether it did.
+func (q *SPSCQueue[T]) TryPush(v T) bool {
+	t := q.tail.Load()
+	if t-q.head.Load() == uint64(len(q.buf)) {
+		return false
+	}
+	q.buf[t&q.mask] = v
+	q.tail.Store(t + 1)
+	return true
+}
+
+// TryPushBatch adds as many elements of vs as fit to the queue,
+// and returns how many it added.
+func (q *SPSCQueue[T]) TryPushBatch(vs []T) int {
+	t := q.tail.Load()
+	n := min(len(vs), len(q.buf)-int(t-q.head.Load()))
+	for i, v := range vs[:n] {
+		q.buf[(t+uint64(i))&q.mask] = v
+	}
+	q.tail.Store(t + uint64(n))
+	return n
+}
+
+// TryPop removes and returns the oldest element of the queue
+// if it's not empty.
+func (q *SPSCQueue[T]) TryPop() (v T, ok bool) {
+	h := q.head.Load()
+	if h == q.tail.Load() {
+		return v, false
+	}
+	i := h & q.mask
+	v = q.buf[i]
+	q.buf[i] = *new(T)
+	q.head.Store(h + 1)
+	return v, true
+}
+
+// TryPopBatch removes up to len(dst) of the oldest elements of the queue
+// into dst, and returns how many it removed.
+func (q *SPSCQueue[T]) TryPopBatch(dst []T) int {
+	h := q.head.Load()
+	n := min(len(dst), int(q.tail.Load()-h))
+	for i := range dst[:n] {
+		j := (h + uint64(i)) & q.mask
+		dst[i] = q.buf[j]
+		q.buf[j] = *new(T)
+	}
+	q.head.Store(h + uint64(n))
+	return n
+}
+
+// Len returns the number of elements in the queue.
+// It's only a snapshot when other goroutines use the queue.
+func (q *SPSCQueue[T]) Len() int {
+	h := q.head.Load()
+	t := q.tail.Load()
+	return int(min(t-h, uint64(len(q.buf))))
+}
+
+func (q *SPSCQueue[T]) Cap() int {
+	return len(q.buf)
+}
+
+// MPMCQueue is a lock-free bounded queue
+// for any number of producer and consumer goroutines.
+//
+// It's an implementation of Dmitry Vyukov's bounded MPMC queue,
+// where each slot has a sequence number that tells producers and consumers
+// whether it's their turn to use it.
+type MPMCQueue[T any] struct {
+	_     cacheLinePad
+	head  atomic.Uint64 // Position of the next pop.
+	_     cacheLinePad
+	tail  atomic.Uint64 // Position of the next push.
+	_     cacheLinePad
+	slots []mpmcSlot[T]
+	mask  uint64
+}
+
+type mpmcSlot[T any] struct {
+	seq atomic.Uint64
+	val T
+}
+
+// NewMPMCQueue returns a new queue with the given capacity
+// rounded up to a power of two, and to at least 2,
+// since the sequence numbers of a single slot can't tell
+// a full queue from an empty one.
+// It panics if capacity is not positive.
+func NewMPMCQueue[T any](capacity int) *MPMCQueue[T] {
+	n := max(queueCap(capacity, "ringbuf.NewMPMCQueue"), 2)
+	q := &MPMCQueue[T]{
+		slots: make([]mpmcSlot[T], n),
+		mask:  n - 1,
+	}
+	for i := range q.slots {
+		q.slots[i].seq.Store(uint64(i))
+	}
+	return q
+}
+
+// TryPush adds v to the queue if it's not full,
+// and reports whether it did.
+func (q *MPMCQueue[T]) TryPush(v T) bool {
+	pos := q.tail.Load()
+	for {
+		s := &q.slots[pos&q.mask]
+		switch d := int64(s.seq.Load() - pos); {
+		case d == 0:
+			if q.tail.CompareAndSwap(pos, pos+1) {
+				s.val = v
+				s.seq.Store(pos + 1)
+				return true
+			}
+			pos = q.tail.Load()
+		case d < 0:
+			return false
+		default:
+			pos = q.tail.Load()
+		}
+	}
+}
+
+// TryPushBatch adds as many elements of vs as fit to the queue,
+// and returns how many it added.
+// Elements pushed concurrently by other producers may be interleaved.
+func (q *MPMCQueue[T]) TryPushBatch(vs []T) int {
+	for i, v := range vs {
+		if !q.TryPush(v) {
+			return i
+		}
+	}
+	return len(vs)
+}
+
+// TryPop removes and returns the oldest element of the queue
+// if it's not empty.
+func (q *MPMCQueue[T]) TryPop() (v T, ok bool) {
+	pos := q.head.Load()
+	for {
+		s := &q.slots[pos&q.mask]
+		switch d := int64(s.seq.Load() - (pos + 1)); {
+		case d == 0:
+			if q.head.CompareAndSwap(pos, pos+1) {
+				v = s.val
+				s.val = *new(T)
+				s.seq.Store(pos + q.mask + 1)
+				return v, true
+			}
+			pos = q.head.Load()
+		case d < 0:
+			return v, false
+		default:
+			pos = q.head.Load()
+		}
+	}
+}
+
+// TryPopBatch removes up to len(dst) of the oldest elements of the queue
+// into dst, and returns how many it removed.
+func (q *MPMCQueue[T]) TryPopBatch(dst []T) int {
+	for i := range dst {
+		v, ok := q.TryPop()
+		if !ok {
+			return i
+		}
+		dst[i] = v
+	}
+	return len(dst)
+}
+
+// Len returns the number of elements in the queue.
+// It's only a snapshot when other goroutines use the queue.
+func (q *MPMCQueue[T]) Len() int {
+	h := q.head.Load()
+	t := q.tail.Load()
+	return int(min(t-h, uint64(len(q.slots))))
+}
+
+func (q *MPMCQueue[T]) Cap() int {
+	return len(q.slots)
+}
+
+// queueCap returns capacity rounded up to a power of two.
+func queueCap(capacity int, caller string) uint64 {
+	if capacity <= 0 {
+		panic(caller + ": capacity <= 0")
+	}
+	return 1 << bits.Len64(uint64(capacity-1))
+}
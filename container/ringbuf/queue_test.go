@@ -0,0 +1,302 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package ringbuf_test
+
+import (
+	"runtime"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/layer8co/toolbox/container/ringbuf"
+)
+
+// queue is implemented by [ringbuf.SPSCQueue] and [ringbuf.MPMCQueue].
+type queue[T any] interface {
+	TryPush(v T) bool
+	TryPushBatch(vs []T) int
+	TryPop() (T, bool)
+	TryPopBatch(dst []T) int
+	Len() int
+	Cap() int
+}
+
+func TestQueue(t *testing.T) {
+
+	queues := []struct {
+		name     string
+		newQueue func(int) queue[int]
+		minCap   int
+	}{
+		{"spsc", func(n int) queue[int] { return ringbuf.NewSPSCQueue[int](n) }, 1},
+		{"mpmc", func(n int) queue[int] { return ringbuf.NewMPMCQueue[int](n) }, 2},
+	}
+
+	for _, test := range queues {
+		newQueue := test.newQueue
+		t.Run(test.name, func(t *testing.T) {
+
+			for capacity, want := range map[int]int{1: 1, 2: 2, 3: 4, 5: 8, 8: 8, 1000: 1024} {
+				want = max(want, test.minCap)
+				if got := newQueue(capacity).Cap(); got != want {
+					t.Errorf("capacity %d: want Cap %d, got %d", capacity, want, got)
+				}
+			}
+
+			// Fill and drain small queues.
+			for _, capacity := range []int{1, 2} {
+				q := newQueue(capacity)
+				for range 3 {
+					n := 0
+					for n <= q.Cap() && q.TryPush(n) {
+						n++
+					}
+					if n != q.Cap() || q.Len() != n {
+						t.Fatalf("capacity %d: pushed %d with Len %d, want %d", capacity, n, q.Len(), q.Cap())
+					}
+					for i := range n {
+						v, ok := q.TryPop()
+						if !ok || v != i {
+							t.Fatalf("capacity %d: TryPop: want %d, true, got %d, %v", capacity, i, v, ok)
+						}
+					}
+					if _, ok := q.TryPop(); ok {
+						t.Fatalf("capacity %d: TryPop on an empty queue succeeded", capacity)
+					}
+				}
+			}
+
+			q := newQueue(4)
+
+			if _, ok := q.TryPop(); ok {
+				t.Error("TryPop on an empty queue succeeded")
+			}
+
+			// Go around the ring a few times.
+			next, popped := 0, 0
+			for range 5 {
+				for q.TryPush(next) {
+					next++
+				}
+				if q.Len() != 4 {
+					t.Fatalf("want Len 4, got %d", q.Len())
+				}
+				for range 3 {
+					v, ok := q.TryPop()
+					if !ok || v != popped {
+						t.Fatalf("TryPop: want %d, true, got %d, %v", popped, v, ok)
+					}
+					popped++
+				}
+			}
+
+			if n := q.TryPushBatch([]int{100, 101, 102, 103}); n != 3 {
+				t.Errorf("TryPushBatch: want 3, got %d", n)
+			}
+
+			dst := make([]int, 8)
+			n := q.TryPopBatch(dst)
+			want := []int{popped, 100, 101, 102}
+			if n != 4 || !slices.Equal(want, dst[:n]) {
+				t.Errorf("TryPopBatch: want %v, got %v", want, dst[:n])
+			}
+			if q.Len() != 0 {
+				t.Errorf("want Len 0, got %d", q.Len())
+			}
+		})
+	}
+}
+
+func TestSPSCQueueConcurrency(t *testing.T) {
+
+	const count = 100_000
+
+	q := ringbuf.NewSPSCQueue[int](64)
+
+	go func() {
+		batch := make([]int, 0, 16)
+		for i := 0; i < count; {
+			if i%3 == 0 {
+				batch = batch[:0]
+				for j := i; j < min(i+16, count); j++ {
+					batch = append(batch, j)
+				}
+				i += q.TryPushBatch(batch)
+			} else if q.TryPush(i) {
+				i++
+			}
+			runtime.Gosched()
+		}
+	}()
+
+	dst := make([]int, 7)
+	for want := 0; want < count; {
+		n := q.TryPopBatch(dst)
+		for _, v := range dst[:n] {
+			if v != want {
+				t.Fatalf("want %d, got %d", want, v)
+			}
+			want++
+		}
+		if n == 0 {
+			runtime.Gosched()
+		}
+	}
+}
+
+func TestMPMCQueueConcurrency(t *testing.T) {
+
+	const (
+		producers = 4
+		consumers = 4
+		count     = 20_000 // Per producer.
+	)
+
+	q := ringbuf.NewMPMCQueue[int](32)
+
+	var wg sync.WaitGroup
+	for p := range producers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < count; {
+				if q.TryPush(p*count + i) {
+					i++
+				} else {
+					runtime.Gosched()
+				}
+			}
+		}()
+	}
+
+	results := make(chan []int, consumers)
+	for range consumers {
+		go func() {
+			var got []int
+			for len(got) < producers*count/consumers {
+				if v, ok := q.TryPop(); ok {
+					got = append(got, v)
+				} else {
+					runtime.Gosched()
+				}
+			}
+			results <- got
+		}()
+	}
+
+	wg.Wait()
+
+	seen := make([]bool, producers*count)
+	for range consumers {
+		got := <-results
+		last := make([]int, producers)
+		for i := range last {
+			last[i] = -1
+		}
+		for _, v := range got {
+			if seen[v] {
+				t.Fatalf("%d was popped twice", v)
+			}
+			seen[v] = true
+			// Elements of each producer are popped in order by each consumer.
+			p := v / count
+			if v <= last[p] {
+				t.Fatalf("%d was popped after %d", v, last[p])
+			}
+			last[p] = v
+		}
+	}
+}
+
+func BenchmarkQueue(b *testing.B) {
+
+	const capacity = 1024
+
+	b.Run("spsc", func(b *testing.B) {
+		q := ringbuf.NewSPSCQueue[int](capacity)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < b.N; {
+				if q.TryPush(i) {
+					i++
+				} else {
+					runtime.Gosched()
+				}
+			}
+		}()
+		for i := 0; i < b.N; {
+			if _, ok := q.TryPop(); ok {
+				i++
+			} else {
+				runtime.Gosched()
+			}
+		}
+		<-done
+	})
+
+	b.Run("spsc-batch", func(b *testing.B) {
+		q := ringbuf.NewSPSCQueue[int](capacity)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			batch := make([]int, 64)
+			for i := 0; i < b.N; {
+				n := q.TryPushBatch(batch[:min(len(batch), b.N-i)])
+				if n == 0 {
+					runtime.Gosched()
+				}
+				i += n
+			}
+		}()
+		dst := make([]int, 64)
+		for i := 0; i < b.N; {
+			n := q.TryPopBatch(dst)
+			if n == 0 {
+				runtime.Gosched()
+			}
+			i += n
+		}
+		<-done
+	})
+
+	b.Run("spsc-chan", func(b *testing.B) {
+		c := make(chan int, capacity)
+		go func() {
+			for i := range b.N {
+				c <- i
+			}
+		}()
+		for range b.N {
+			<-c
+		}
+	})
+
+	b.Run("mpmc", func(b *testing.B) {
+		q := ringbuf.NewMPMCQueue[int](capacity)
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				for !q.TryPush(1) {
+					runtime.Gosched()
+				}
+				for {
+					if _, ok := q.TryPop(); ok {
+						break
+					}
+					runtime.Gosched()
+				}
+			}
+		})
+	})
+
+	b.Run("mpmc-chan", func(b *testing.B) {
+		c := make(chan int, capacity)
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				c <- 1
+				<-c
+			}
+		})
+	})
+}
@@ -0,0 +1,108 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package ringbuf
+
+import (
+	"errors"
+	"io"
+)
+
+// Reader reads the elements of a [Buffer] from its own position,
+// independently of the read position of the buffer and of other readers,
+// which lets multiple consumers follow the same buffer.
+//
+// Like the buffer, it's not safe for concurrent use;
+// reads must be synchronized with modifications of the buffer.
+type Reader[T any] struct {
+	b   *Buffer[T]
+	off int64
+	gen uint64 // Truncation generation of the buffer at off.
+}
+
+// NewReader returns a new reader of the buffer,
+// positioned at the oldest element the buffer retains.
+func (b *Buffer[T]) NewReader() *Reader[T] {
+	return &Reader[T]{
+		b:   b,
+		off: b.Start(),
+		gen: b.truncGen,
+	}
+}
+
+// Read reads the elements at the position of the reader.
+// It returns [io.EOF] when it has caught up with the buffer.
+//
+// If the elements at the position of the reader have been overwritten,
+// it returns [ErrOverwritten] without reading,
+// and moves the reader to the oldest element the buffer retains,
+// so that the next read continues from there.
+// The number of lost elements is the difference in [Reader.Offset].
+//
+// If the buffer has been truncated to before the position of the reader,
+// like by [Buffer.Truncate], the reader moves back to where it was truncated,
+// so that it reads the elements written since in place of the dropped ones.
+func (r *Reader[T]) Read(dest []T) (int, error) {
+	r.sync()
+	if start := r.b.Start(); r.off < start {
+		r.off = start
+		return 0, ErrOverwritten
+	}
+	n, err := r.b.ReadAt(dest, r.off)
+	r.off += int64(n)
+	return n, err
+}
+
+// Seek implements [io.Seeker] like [Buffer.Seek],
+// but for the position of the reader.
+func (r *Reader[T]) Seek(offset int64, whence int) (int64, error) {
+
+	var abs int64
+	switch whence {
+	case io.SeekStart:
+		abs = offset
+	case io.SeekCurrent:
+		r.sync()
+		abs = r.off + offset
+	case io.SeekEnd:
+		abs = r.b.written + offset
+	default:
+		return 0, errors.New("ringbuf.Reader.Seek: invalid whence")
+	}
+
+	switch {
+	case abs < 0:
+		return 0, ErrNegativeOffset
+	case abs < r.b.Start():
+		return 0, ErrOverwritten
+	case abs > r.b.written:
+		return 0, ErrOutOfRange
+	}
+
+	r.off = abs
+	r.gen = r.b.truncGen
+	return abs, nil
+}
+
+// Offset returns the stream offset of the next element to be read.
+func (r *Reader[T]) Offset() int64 {
+	r.sync()
+	return r.off
+}
+
+// Len returns the number of elements the reader has yet to read,
+// not counting elements that have been overwritten.
+func (r *Reader[T]) Len() int {
+	r.sync()
+	return max(int(r.b.written-max(r.off, r.b.Start())), 0)
+}
+
+// sync moves the reader back to where the buffer has been truncated,
+// if it has been truncated to before the position of the reader
+// since the reader last caught up with it.
+func (r *Reader[T]) sync() {
+	if off, ok := r.b.truncatedSince(r.gen); ok && r.off > off {
+		r.off = off
+	}
+	r.gen = r.b.truncGen
+}
@@ -0,0 +1,105 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package ringbuf_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/layer8co/toolbox/container/ringbuf"
+)
+
+func TestReader(t *testing.T) {
+
+	b := ringbuf.NewByteBuffer(8)
+	r1 := b.NewReader()
+	r2 := b.NewReader()
+
+	b.WriteString("hello")
+	got, err := io.ReadAll(r1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff(t, "r1", "hello", got)
+
+	b.WriteString(" world")
+	assertEqual(t, "r1.Len", 6, r1.Len())
+	assertEqual(t, "r2.Len", 8, r2.Len())
+
+	got, _ = io.ReadAll(r1)
+	diff(t, "r1 after write", " world", got)
+
+	// r2 lost the data it didn't read in time.
+	if _, err := r2.Read(make([]byte, 1)); !errors.Is(err, ringbuf.ErrOverwritten) {
+		t.Errorf("r2: want ErrOverwritten, got %v", err)
+	}
+	assertEqual(t, "r2.Offset", 3, r2.Offset())
+	got, _ = io.ReadAll(r2)
+	diff(t, "r2 after overwrite", "lo world", got)
+
+	// Readers don't affect the buffer or each other.
+	diff(t, "Bytes", "lo world", b.Bytes())
+	r3 := b.NewReader()
+	assertEqual(t, "r3.Offset", 3, r3.Offset())
+
+	if _, err := r3.Seek(2, io.SeekStart); !errors.Is(err, ringbuf.ErrOverwritten) {
+		t.Errorf("Seek before start: want ErrOverwritten, got %v", err)
+	}
+	if _, err := r3.Seek(1, io.SeekEnd); !errors.Is(err, ringbuf.ErrOutOfRange) {
+		t.Errorf("Seek after end: want ErrOutOfRange, got %v", err)
+	}
+	if off, err := r3.Seek(-5, io.SeekEnd); off != 6 || err != nil {
+		t.Errorf("Seek(-5, io.SeekEnd): want 6, nil, got %d, %v", off, err)
+	}
+	got, _ = io.ReadAll(r3)
+	diff(t, "r3", "world", got)
+}
+
+func TestReaderTruncate(t *testing.T) {
+
+	b := ringbuf.NewByteBuffer(16)
+	r1 := b.NewReader()
+	r2 := b.NewReader()
+	r3 := b.NewReader()
+
+	b.WriteString("abcdef")
+	got, _ := io.ReadAll(r1)
+	diff(t, "r1", "abcdef", got)
+	r2.Read(make([]byte, 1))
+
+	// Readers past the kept elements move back to them,
+	// and read what is written in place of the dropped ones.
+	b.Truncate(2)
+	b.WriteString("XYZ")
+	got, _ = io.ReadAll(r1)
+	diff(t, "r1 after truncate", "XYZ", got)
+	assertEqual(t, "r1.Offset", 5, r1.Offset())
+
+	// Readers before them are unaffected.
+	got, _ = io.ReadAll(r2)
+	diff(t, "r2 after truncate", "bXYZ", got)
+
+	// Readers catch up on the lowest point the buffer was truncated to,
+	// across several truncations and over Seek.
+	b.Truncate(1)
+	b.WriteString("12345")
+	b.Truncate(4)
+	diff(t, "Bytes", "a123", b.Bytes())
+	assertEqual(t, "r1.Len", 3, r1.Len())
+	if off, err := r1.Seek(0, io.SeekCurrent); off != 1 || err != nil {
+		t.Errorf("r1.Seek(0, io.SeekCurrent): want 1, nil, got %d, %v", off, err)
+	}
+	got, _ = io.ReadAll(r1)
+	diff(t, "r1 after truncates", "123", got)
+	got, _ = io.ReadAll(r3)
+	diff(t, "r3", "a123", got)
+
+	b.SetMaxLen(2, ringbuf.DropNewest)
+	b.WriteString("!")
+	got, _ = io.ReadAll(r1)
+	diff(t, "r1 after SetMaxLen", "!", got)
+	got, _ = io.ReadAll(r2)
+	diff(t, "r2 after SetMaxLen", "1!", got)
+}
@@ -117,13 +117,19 @@ func readWriterTo(w io.WriterTo) []byte {
 	return buf.Bytes()
 }
 
-func readReaderAt(r io.ReaderAt) (b []byte) {
+// readReaderAt reads the data retained by r
+// from its oldest retained offset.
+func readReaderAt(r interface {
+	io.ReaderAt
+	Start() int64
+}) (b []byte) {
+	start := r.Start()
 	off := 0
 	for {
 		if off == cap(b) {
 			b = slices.Grow(b, 128)
 		}
-		n, err := r.ReadAt(b[off:cap(b)], int64(off))
+		n, err := r.ReadAt(b[off:cap(b)], start+int64(off))
 		off += n
 		b = b[:off]
 		if err == io.EOF {
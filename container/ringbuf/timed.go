@@ -0,0 +1,184 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package ringbuf
+
+import (
+	"iter"
+	"sort"
+	"time"
+)
+
+// Record is an element of a [TimedBuffer].
+type Record[T any] struct {
+	Time  time.Time
+	Value T
+}
+
+// TimedBuffer is a ring buffer of timestamped records,
+// like the recent values of a metric or recent events,
+// that drops records once they're older than a maximum age,
+// or when it's full.
+//
+// Records are kept in the order they're added,
+// and their times never decrease.
+//
+// A TimedBuffer must be created using [NewTimedBuffer].
+type TimedBuffer[T any] struct {
+	c TimedBufferConfig
+
+	// Expired records are before the read position of b.
+	b Buffer[Record[T]]
+}
+
+// TimedBufferConfig configures a [TimedBuffer].
+type TimedBufferConfig struct {
+
+	// MaxLen is the maximum number of records.
+	// It must be positive.
+	MaxLen int
+
+	// MaxAge is the age after which records are dropped.
+	// Records don't expire if it's not positive.
+	MaxAge time.Duration
+
+	// Now returns the current time.
+	// It defaults to [time.Now].
+	Now func() time.Time
+}
+
+// NewTimedBuffer returns a new timed buffer.
+// It panics if c.MaxLen is not positive.
+func NewTimedBuffer[T any](c TimedBufferConfig) *TimedBuffer[T] {
+	if c.MaxLen <= 0 {
+		panic("ringbuf.NewTimedBuffer: MaxLen <= 0")
+	}
+	if c.Now == nil {
+		c.Now = time.Now
+	}
+	t := &TimedBuffer[T]{
+		c: c,
+	}
+	t.b.maxLen = c.MaxLen
+	return t
+}
+
+// Add adds a record of v at the current time.
+func (t *TimedBuffer[T]) Add(v T) {
+	t.AddAt(t.c.Now(), v)
+}
+
+// AddAt adds a record of v at time tm.
+// If tm is before the time of the newest record,
+// the record gets the time of the newest record instead,
+// to keep the records in order.
+func (t *TimedBuffer[T]) AddAt(tm time.Time, v T) {
+	if n := t.b.Len(); n > 0 {
+		if last := t.at(n - 1).Time; tm.Before(last) {
+			tm = last
+		}
+	}
+	t.b.Write([]Record[T]{{tm, v}})
+	t.expire()
+}
+
+// All returns an iterator over the records that haven't expired,
+// from the oldest to the newest.
+func (t *TimedBuffer[T]) All() iter.Seq2[time.Time, T] {
+	return t.Range(time.Time{}, time.Time{})
+}
+
+// Since returns an iterator over the records
+// with a time not before tm, from the oldest to the newest.
+func (t *TimedBuffer[T]) Since(tm time.Time) iter.Seq2[time.Time, T] {
+	return t.Range(tm, time.Time{})
+}
+
+// Range returns an iterator over the records
+// with a time in the interval [from, to), from the oldest to the newest.
+// A zero from or to leaves that end of the interval open.
+func (t *TimedBuffer[T]) Range(from, to time.Time) iter.Seq2[time.Time, T] {
+	return func(yield func(time.Time, T) bool) {
+		t.expire()
+		i, j := 0, t.b.Len()
+		if !from.IsZero() {
+			i = t.search(from)
+		}
+		if !to.IsZero() {
+			j = t.search(to)
+		}
+		for ; i < j; i++ {
+			r := t.at(i)
+			if !yield(r.Time, r.Value) {
+				return
+			}
+		}
+	}
+}
+
+// Len returns the number of records that haven't expired.
+func (t *TimedBuffer[T]) Len() int {
+	t.expire()
+	return t.b.Len()
+}
+
+// Oldest returns the oldest record that hasn't expired.
+func (t *TimedBuffer[T]) Oldest() (r Record[T], ok bool) {
+	if t.Len() == 0 {
+		return r, false
+	}
+	return t.at(0), true
+}
+
+// Newest returns the newest record that hasn't expired.
+func (t *TimedBuffer[T]) Newest() (r Record[T], ok bool) {
+	n := t.Len()
+	if n == 0 {
+		return r, false
+	}
+	return t.at(n - 1), true
+}
+
+func (t *TimedBuffer[T]) Reset() {
+	clear(t.b.buf)
+	t.b.Reset()
+}
+
+// expire drops the records that are older than the maximum age.
+func (t *TimedBuffer[T]) expire() {
+
+	if t.c.MaxAge <= 0 {
+		return
+	}
+
+	n := t.search(t.c.Now().Add(-t.c.MaxAge))
+	if n == 0 {
+		return
+	}
+
+	// Zero the records so that their values can be garbage collected.
+	m := n
+	for s := range t.b.seq(t.b.readPos) {
+		s = s[:min(len(s), m)]
+		clear(s)
+		m -= len(s)
+		if m == 0 {
+			break
+		}
+	}
+
+	t.b.readPos += n
+}
+
+// search returns the index of the first unread record
+// with a time not before tm, or the number of unread records if none.
+func (t *TimedBuffer[T]) search(tm time.Time) int {
+	return sort.Search(t.b.Len(), func(i int) bool {
+		return !t.at(i).Time.Before(tm)
+	})
+}
+
+// at returns the unread record at index i.
+func (t *TimedBuffer[T]) at(i int) Record[T] {
+	return t.b.buf[(t.b.writePos+t.b.readPos+i)%len(t.b.buf)]
+}
@@ -0,0 +1,129 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package ringbuf_test
+
+import (
+	"iter"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/layer8co/toolbox/container/ringbuf"
+)
+
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func (c *clock) advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func values[T any](seq iter.Seq2[time.Time, T]) []T {
+	var vs []T
+	for _, v := range seq {
+		vs = append(vs, v)
+	}
+	return vs
+}
+
+func TestTimedBuffer(t *testing.T) {
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := &clock{now: start}
+	b := ringbuf.NewTimedBuffer[int](ringbuf.TimedBufferConfig{
+		MaxLen: 5,
+		MaxAge: 5 * time.Minute,
+		Now:    c.Now,
+	})
+
+	for i := range 4 {
+		b.Add(i)
+		c.advance(time.Minute)
+	}
+
+	testCases := []struct {
+		line int
+		seq  iter.Seq2[time.Time, int]
+		want []int
+	}{
+		{line(), b.All(), []int{0, 1, 2, 3}},
+		{line(), b.Since(start.Add(2 * time.Minute)), []int{2, 3}},
+		{line(), b.Since(start.Add(90 * time.Second)), []int{2, 3}},
+		{line(), b.Since(start.Add(time.Hour)), nil},
+		{line(), b.Range(start.Add(time.Minute), start.Add(3*time.Minute)), []int{1, 2}},
+		{line(), b.Range(time.Time{}, start.Add(time.Minute)), []int{0}},
+	}
+	for _, tt := range testCases {
+		if diff := cmp.Diff(tt.want, values(tt.seq)); diff != "" {
+			t.Errorf("line %d: incorrect result (-want +got):\n%s", tt.line, diff)
+		}
+	}
+
+	// Records expire once they're older than MaxAge.
+	c.advance(90 * time.Second)
+	if diff := cmp.Diff([]int{1, 2, 3}, values(b.All())); diff != "" {
+		t.Errorf("after expiry: incorrect result (-want +got):\n%s", diff)
+	}
+	assertEqual(t, "Len", 3, b.Len())
+
+	// Records are dropped when the buffer is full.
+	for i := range 4 {
+		b.Add(4 + i)
+	}
+	if diff := cmp.Diff([]int{3, 4, 5, 6, 7}, values(b.All())); diff != "" {
+		t.Errorf("after overflow: incorrect result (-want +got):\n%s", diff)
+	}
+
+	// Times never decrease.
+	b.AddAt(start, 8)
+	r, _ := b.Newest()
+	assertEqual(t, "Newest", c.now, r.Time)
+	r, _ = b.Oldest()
+	assertEqual(t, "Oldest", 4, r.Value)
+
+	c.advance(time.Hour)
+	assertEqual(t, "Len after an hour", 0, b.Len())
+	if _, ok := b.Oldest(); ok {
+		t.Error("Oldest: want no record")
+	}
+}
+
+func TestTimedBufferModel(t *testing.T) {
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for seed := range 50 {
+
+		c := &clock{now: start}
+		b := ringbuf.NewTimedBuffer[int](ringbuf.TimedBufferConfig{
+			MaxLen: 1 + seed%7,
+			MaxAge: time.Duration(1+seed%5) * time.Second,
+			Now:    c.Now,
+		})
+
+		var model []ringbuf.Record[int]
+		for i := range 200 {
+
+			c.advance(time.Duration((i*seed)%1500) * time.Millisecond)
+			b.Add(i)
+			model = append(model, ringbuf.Record[int]{Time: c.now, Value: i})
+
+			cutoff := c.now.Add(-time.Duration(1+seed%5) * time.Second)
+			var want []int
+			for _, r := range model[max(len(model)-(1+seed%7), 0):] {
+				if !r.Time.Before(cutoff) {
+					want = append(want, r.Value)
+				}
+			}
+			if diff := cmp.Diff(want, values(b.All())); diff != "" {
+				t.Fatalf("seed %d, add %d: incorrect result (-want +got):\n%s", seed, i, diff)
+			}
+		}
+	}
+}
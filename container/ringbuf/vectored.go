@@ -0,0 +1,62 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package ringbuf
+
+import (
+	"errors"
+	"io"
+	"net"
+	"os"
+	"syscall"
+)
+
+// writeSegs writes segs to w,
+// using a single vectored write (writev) where possible.
+func writeSegs(w io.Writer, segs [][]byte) (n int64, err error) {
+
+	switch w := w.(type) {
+	case *os.File:
+		n, err := writevFile(w, segs)
+		if err != errors.ErrUnsupported {
+			return n, err
+		}
+	case net.Conn:
+		bufs := net.Buffers(segs)
+		return bufs.WriteTo(w)
+	}
+
+	for _, s := range segs {
+		m, err := w.Write(s)
+		n += int64(m)
+		if err != nil {
+			return n, err
+		}
+		if m < len(s) {
+			return n, io.ErrShortWrite
+		}
+	}
+	return n, nil
+}
+
+// readSegs reads from r into segs, in order,
+// using a single vectored read (readv) where possible.
+// Otherwise, it only reads into the first segment.
+func readSegs(r io.Reader, segs [][]byte) (int, error) {
+	// Only types whose Read is known to be a plain read(2),
+	// since wrappers that embed them may override Read.
+	var c syscall.Conn
+	switch r := r.(type) {
+	case *os.File:
+		c = r
+	case *net.TCPConn:
+		c = r
+	}
+	if c != nil && len(segs) > 1 {
+		n, err := readvConn(c, segs)
+		if err != errors.ErrUnsupported {
+			return n, err
+		}
+	}
+	return r.Read(segs[0])
+}
@@ -0,0 +1,117 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux
+
+package ringbuf
+
+import (
+	"io"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+func writevFile(f *os.File, segs [][]byte) (n int64, err error) {
+
+	rc, err := f.SyscallConn()
+	if err != nil {
+		return 0, err
+	}
+
+	var iovs [2]syscall.Iovec
+	for {
+
+		iov := makeIovecs(iovs[:0], segs)
+		if len(iov) == 0 {
+			return n, nil
+		}
+
+		var m uintptr
+		var errno syscall.Errno
+		err := rc.Write(func(fd uintptr) bool {
+			m, _, errno = syscall.Syscall(
+				syscall.SYS_WRITEV,
+				fd,
+				uintptr(unsafe.Pointer(&iov[0])),
+				uintptr(len(iov)),
+			)
+			return errno != syscall.EAGAIN
+		})
+		if err != nil {
+			return n, err
+		}
+		switch errno {
+		case 0:
+		case syscall.EINTR:
+			continue
+		default:
+			return n, &os.PathError{Op: "writev", Path: f.Name(), Err: errno}
+		}
+
+		n += int64(m)
+		segs = consumeSegs(segs, int(m))
+	}
+}
+
+func readvConn(c syscall.Conn, segs [][]byte) (int, error) {
+
+	rc, err := c.SyscallConn()
+	if err != nil {
+		return 0, err
+	}
+
+	var iovs [2]syscall.Iovec
+	iov := makeIovecs(iovs[:0], segs)
+	if len(iov) == 0 {
+		return 0, nil
+	}
+
+	for {
+		var n uintptr
+		var errno syscall.Errno
+		err := rc.Read(func(fd uintptr) bool {
+			n, _, errno = syscall.Syscall(
+				syscall.SYS_READV,
+				fd,
+				uintptr(unsafe.Pointer(&iov[0])),
+				uintptr(len(iov)),
+			)
+			return errno != syscall.EAGAIN
+		})
+		switch {
+		case err != nil:
+			return 0, err
+		case errno == syscall.EINTR:
+			continue
+		case errno != 0:
+			return 0, os.NewSyscallError("readv", errno)
+		case n == 0:
+			return 0, io.EOF
+		}
+		return int(n), nil
+	}
+}
+
+func makeIovecs(iovs []syscall.Iovec, segs [][]byte) []syscall.Iovec {
+	for _, s := range segs {
+		if len(s) > 0 {
+			iov := syscall.Iovec{Base: &s[0]}
+			iov.SetLen(len(s))
+			iovs = append(iovs, iov)
+		}
+	}
+	return iovs
+}
+
+// consumeSegs drops the first n bytes of segs.
+func consumeSegs(segs [][]byte, n int) [][]byte {
+	for len(segs) > 0 && n >= len(segs[0]) {
+		n -= len(segs[0])
+		segs = segs[1:]
+	}
+	if len(segs) > 0 {
+		segs[0] = segs[0][n:]
+	}
+	return segs
+}
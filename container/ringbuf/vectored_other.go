@@ -0,0 +1,20 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !linux
+
+package ringbuf
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+func writevFile(f *os.File, segs [][]byte) (int64, error) {
+	return 0, errors.ErrUnsupported
+}
+
+func readvConn(c syscall.Conn, segs [][]byte) (int, error) {
+	return 0, errors.ErrUnsupported
+}
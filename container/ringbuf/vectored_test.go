@@ -0,0 +1,250 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package ringbuf_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/layer8co/toolbox/container/ringbuf"
+)
+
+// wrappedBuffer returns a buffer whose data wraps around its end,
+// along with the data.
+func wrappedBuffer(maxLen int) (*ringbuf.ByteBuffer, []byte) {
+	data := make([]byte, maxLen+maxLen/2)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	b := ringbuf.NewByteBuffer(maxLen)
+	b.Write(data[:maxLen])
+	b.Write(data[maxLen:])
+	return b, data[len(data)-maxLen:]
+}
+
+// onlyWriter hides the concrete type of a writer.
+type onlyWriter struct{ io.Writer }
+
+// onlyReader hides the concrete type of a reader.
+type onlyReader struct{ io.Reader }
+
+// upperFile embeds a file, but overrides its Read.
+type upperFile struct{ *os.File }
+
+func (f upperFile) Read(p []byte) (int, error) {
+	n, err := f.File.Read(p)
+	copy(p, bytes.ToUpper(p[:n]))
+	return n, err
+}
+
+type shortWriter struct{}
+
+func (shortWriter) Write(p []byte) (int, error) {
+	return len(p) / 2, nil
+}
+
+func TestWriteTo(t *testing.T) {
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	client, server := tcpPipe(t)
+	bb := new(bytes.Buffer)
+
+	for _, tt := range []struct {
+		name string
+		w    io.Writer
+		read func() []byte
+	}{
+		{"file", f, func() []byte {
+			b, _ := os.ReadFile(f.Name())
+			f.Truncate(0)
+			f.Seek(0, io.SeekStart)
+			return b
+		}},
+		{"tcp", client, func() []byte {
+			b := make([]byte, 1000)
+			io.ReadFull(server, b)
+			return b
+		}},
+		{"writer", onlyWriter{bb}, bb.Bytes},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			b, want := wrappedBuffer(1000)
+			n, err := b.WriteTo(tt.w)
+			if n != 1000 || err != nil {
+				t.Fatalf("want 1000, nil, got %d, %v", n, err)
+			}
+			diff(t, "written", want, tt.read())
+			assertEqual(t, "Len", 0, b.Len())
+		})
+	}
+
+	b, _ := wrappedBuffer(10)
+	if n, err := b.WriteTo(shortWriter{}); n != 2 || err != io.ErrShortWrite {
+		t.Errorf("short write: want 2, io.ErrShortWrite, got %d, %v", n, err)
+	}
+}
+
+func TestReadFrom(t *testing.T) {
+
+	data := make([]byte, 10000)
+	for i := range data {
+		data[i] = byte(rand.IntN(256))
+	}
+	path := filepath.Join(t.TempDir(), "in")
+	if err := os.WriteFile(path, data, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, maxLen := range []int{1, 100, 777, 9999, 10000, 20000} {
+		for _, hide := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%d-%v", maxLen, hide), func(t *testing.T) {
+
+				f, err := os.Open(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer f.Close()
+
+				var r io.Reader = f
+				if hide {
+					r = onlyReader{f}
+				}
+
+				// Start with data that wraps around.
+				b, _ := wrappedBuffer(maxLen)
+				b.Reset()
+				b.Write(data[:maxLen/3])
+
+				n, err := b.ReadFrom(r)
+				if n != int64(len(data)) || err != nil {
+					t.Fatalf("want %d, nil, got %d, %v", len(data), n, err)
+				}
+
+				want := append(data[:maxLen/3:maxLen/3], data...)
+				want = want[max(len(want)-maxLen, 0):]
+				diff(t, "Bytes", want, b.Bytes())
+			})
+		}
+	}
+}
+
+func TestReadFromWrapper(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "in")
+	if err := os.WriteFile(path, []byte("abcdef"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	// A full buffer reads into both of its segments.
+	b := ringbuf.NewByteBuffer(8)
+	b.WriteString("12345678")
+	b.ReadFrom(upperFile{f})
+	diff(t, "Bytes", "78ABCDEF", b.Bytes())
+}
+
+func tcpPipe(t testing.TB) (client, server net.Conn) {
+
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer l.Close()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		server, err = l.Accept()
+	}()
+	client, err2 := net.Dial("tcp", l.Addr().String())
+	<-done
+	if err2 != nil || err != nil {
+		t.Fatal(err2, err)
+	}
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+	return client, server
+}
+
+var benchSizes = []int{4 << 10, 64 << 10, 1 << 20, 16 << 20}
+
+func BenchmarkWriteTo(b *testing.B) {
+
+	f, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer f.Close()
+
+	for _, size := range benchSizes {
+		for _, dst := range []struct {
+			name string
+			w    io.Writer
+		}{
+			{"writev", f},
+			{"write", onlyWriter{f}},
+		} {
+			b.Run(fmt.Sprintf("%s/%dKiB", dst.name, size>>10), func(b *testing.B) {
+				buf, _ := wrappedBuffer(size)
+				b.SetBytes(int64(size))
+				for b.Loop() {
+					buf.Seek(buf.Start(), io.SeekStart)
+					buf.WriteTo(dst.w)
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkReadFrom(b *testing.B) {
+
+	path := filepath.Join(b.TempDir(), "in")
+	const fileSize = 32 << 20
+	if err := os.WriteFile(path, make([]byte, fileSize), 0666); err != nil {
+		b.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer f.Close()
+
+	for _, size := range benchSizes {
+		for _, src := range []struct {
+			name string
+			r    io.Reader
+		}{
+			{"readv", f},
+			{"read", onlyReader{f}},
+		} {
+			b.Run(fmt.Sprintf("%s/%dKiB", src.name, size>>10), func(b *testing.B) {
+				buf, _ := wrappedBuffer(size)
+				b.SetBytes(int64(size))
+				for b.Loop() {
+					f.Seek(int64(fileSize-size), io.SeekStart)
+					buf.ReadFrom(src.r)
+				}
+			})
+		}
+	}
+}
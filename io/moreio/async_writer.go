@@ -0,0 +1,195 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package moreio
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// AsyncWriter is returned by [NewAsyncWriter].
+// See it's documentation for details.
+type AsyncWriter struct {
+	w    io.Writer
+	mu   sync.Mutex
+	cond sync.Cond
+
+	buf     []byte // The buffer being filled by writes.
+	pending []byte // The buffer being flushed, or nil.
+	spare   []byte // The other buffer, when no flush is in progress.
+
+	err    error // The first downstream or context error.
+	closed bool
+	done   chan struct{}
+	stop   func() bool
+}
+
+// NewAsyncWriter returns a writer that buffers data written to it,
+// and writes it to w in the background,
+// so that writes don't wait for a slow w (like a disk or network).
+//
+// It has two buffers of size bytes:
+// writes fill one of them while the other one is written to w.
+// Writes block when both buffers are full.
+//
+// Errors returned by w are returned by subsequent calls to
+// [AsyncWriter.Write], [AsyncWriter.Flush] and [AsyncWriter.Close],
+// after which the buffered data is discarded.
+//
+// [AsyncWriter.Close] must be called to release the background goroutine.
+// It doesn't close w.
+//
+// NewAsyncWriter panics if size is not positive.
+func NewAsyncWriter(w io.Writer, size int) *AsyncWriter {
+	return NewAsyncWriterContext(context.Background(), w, size)
+}
+
+// NewAsyncWriterContext is like [NewAsyncWriter],
+// but once ctx is done, the writer stops writing to w,
+// and its methods return the error of ctx.
+// A write to w that's in progress isn't interrupted.
+func NewAsyncWriterContext(ctx context.Context, w io.Writer, size int) *AsyncWriter {
+	if size <= 0 {
+		panic("NewAsyncWriter: size <= 0")
+	}
+	a := &AsyncWriter{
+		w:     w,
+		buf:   make([]byte, 0, size),
+		spare: make([]byte, 0, size),
+		done:  make(chan struct{}),
+	}
+	a.cond.L = &a.mu
+	a.stop = context.AfterFunc(ctx, func() {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		if a.err == nil {
+			a.err = ctx.Err()
+		}
+		a.cond.Broadcast()
+	})
+	go a.run()
+	return a
+}
+
+// run writes the pending buffers to w until the writer is closed,
+// or an error occurs.
+func (a *AsyncWriter) run() {
+
+	defer close(a.done)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for {
+
+		for a.pending == nil && !a.closed && a.err == nil {
+			a.cond.Wait()
+		}
+		if a.pending == nil || a.err != nil {
+			return
+		}
+
+		p := a.pending
+		a.mu.Unlock()
+		n, err := a.w.Write(p)
+		if err == nil && n < len(p) {
+			err = io.ErrShortWrite
+		}
+		a.mu.Lock()
+
+		if err != nil && a.err == nil {
+			a.err = err
+		}
+		a.spare = p[:0]
+		a.pending = nil
+		a.cond.Broadcast()
+	}
+}
+
+// Write copies b into the buffers.
+// It blocks while both buffers are full.
+func (a *AsyncWriter) Write(b []byte) (n int, err error) {
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for len(b) > 0 {
+
+		switch {
+		case a.err != nil:
+			return n, a.err
+		case a.closed:
+			return n, ErrWriteAfterClose
+		case len(a.buf) == cap(a.buf):
+			if !a.handOff() {
+				a.cond.Wait()
+			}
+			continue
+		}
+
+		m := copy(a.buf[len(a.buf):cap(a.buf)], b)
+		a.buf = a.buf[:len(a.buf)+m]
+		b = b[m:]
+		n += m
+	}
+
+	return n, nil
+}
+
+func (a *AsyncWriter) WriteString(s string) (int, error) {
+	return a.Write([]byte(s))
+}
+
+// handOff passes the buffer being filled to the background goroutine,
+// if it's not busy.
+func (a *AsyncWriter) handOff() bool {
+	if a.pending != nil {
+		return false
+	}
+	a.pending, a.buf, a.spare = a.buf, a.spare, nil
+	a.cond.Broadcast()
+	return true
+}
+
+// Flush waits until all the data written so far has been written to w.
+func (a *AsyncWriter) Flush() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.flush()
+}
+
+func (a *AsyncWriter) flush() error {
+	for a.err == nil && (len(a.buf) > 0 || a.pending != nil) {
+		if len(a.buf) == 0 || !a.handOff() {
+			a.cond.Wait()
+		}
+	}
+	return a.err
+}
+
+// Buffered returns the number of bytes
+// that haven't been written to w yet.
+func (a *AsyncWriter) Buffered() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.buf) + len(a.pending)
+}
+
+// Close flushes the buffers, waits for the background goroutine to exit,
+// and returns the first error that occurred.
+// Subsequent writes return [ErrWriteAfterClose],
+// and subsequent calls to Close return the same error.
+func (a *AsyncWriter) Close() error {
+
+	a.mu.Lock()
+	err := a.flush()
+	a.closed = true
+	a.cond.Broadcast()
+	a.mu.Unlock()
+
+	<-a.done
+	a.stop()
+	return err
+}
@@ -0,0 +1,212 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package moreio_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/layer8co/toolbox/io/moreio"
+)
+
+// gateWriter is a writer whose writes block until they're allowed.
+type gateWriter struct {
+	mu    sync.Mutex
+	buf   bytes.Buffer
+	gate  chan struct{}
+	calls chan int
+}
+
+func newGateWriter() *gateWriter {
+	return &gateWriter{
+		gate:  make(chan struct{}),
+		calls: make(chan int, 100),
+	}
+}
+
+func (w *gateWriter) Write(p []byte) (int, error) {
+	w.calls <- len(p)
+	<-w.gate
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *gateWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+// slowWriter yields to other goroutines before each write,
+// so that writes interleave with the flushes.
+type slowWriter struct {
+	bytes.Buffer
+}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	for range rand.IntN(10) {
+		runtime.Gosched()
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestAsyncWriter(t *testing.T) {
+
+	for seed := range uint64(20) {
+
+		r := rand.New(rand.NewPCG(seed, seed))
+
+		var dst slowWriter
+		w := moreio.NewAsyncWriter(&dst, r.IntN(100)+1)
+
+		var want []byte
+		for range 200 {
+			p := make([]byte, r.IntN(300))
+			for i := range p {
+				p[i] = byte(r.Uint32())
+			}
+			want = append(want, p...)
+			if n, err := w.Write(p); n != len(p) || err != nil {
+				t.Fatalf("seed %d: Write: want %d, nil, got %d, %v", seed, len(p), n, err)
+			}
+			if r.IntN(20) == 0 {
+				if err := w.Flush(); err != nil {
+					t.Fatalf("seed %d: Flush: %v", seed, err)
+				}
+				if !bytes.Equal(want, dst.Bytes()) || w.Buffered() != 0 {
+					t.Fatalf("seed %d: incomplete Flush", seed)
+				}
+			}
+		}
+
+		if err := w.Close(); err != nil {
+			t.Fatalf("seed %d: Close: %v", seed, err)
+		}
+		if !bytes.Equal(want, dst.Bytes()) {
+			t.Fatalf("seed %d: incorrect output", seed)
+		}
+		if _, err := w.Write([]byte("x")); err != moreio.ErrWriteAfterClose {
+			t.Fatalf("seed %d: Write after Close: want ErrWriteAfterClose, got %v", seed, err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("seed %d: second Close: %v", seed, err)
+		}
+	}
+}
+
+func TestAsyncWriterBackpressure(t *testing.T) {
+
+	dst := newGateWriter()
+	w := moreio.NewAsyncWriter(dst, 4)
+
+	// Fill both buffers while the first one is being flushed.
+	w.Write([]byte("abcd"))
+	w.Write([]byte("efgh"))
+	if n := <-dst.calls; n != 4 {
+		t.Fatalf("incorrect flush length: want 4, got %d", n)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w.Write([]byte("ij"))
+	}()
+
+	select {
+	case <-done:
+		t.Fatalf("Write didn't block with full buffers")
+	case <-time.After(10 * time.Millisecond):
+	}
+	if n := w.Buffered(); n != 8 {
+		t.Fatalf("Buffered: want 8, got %d", n)
+	}
+
+	dst.gate <- struct{}{}
+	<-done
+
+	close(dst.gate)
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if got := dst.String(); got != "abcdefghij" {
+		t.Fatalf("incorrect output: want %q, got %q", "abcdefghij", got)
+	}
+}
+
+type errWriter struct {
+	n   int
+	err error
+}
+
+func (w *errWriter) Write(p []byte) (int, error) {
+	return min(w.n, len(p)), w.err
+}
+
+func TestAsyncWriterError(t *testing.T) {
+
+	errWrite := errors.New("write failed")
+
+	for _, test := range []struct {
+		dst io.Writer
+		err error
+	}{
+		{&errWriter{0, errWrite}, errWrite},
+		{&errWriter{2, nil}, io.ErrShortWrite},
+	} {
+
+		w := moreio.NewAsyncWriter(test.dst, 4)
+
+		var err error
+		for i := 0; err == nil && i < 100; i++ {
+			_, err = w.Write([]byte("abc"))
+		}
+		if err != test.err {
+			t.Errorf("Write: want %v, got %v", test.err, err)
+		}
+		if err := w.Flush(); err != test.err {
+			t.Errorf("Flush: want %v, got %v", test.err, err)
+		}
+		if err := w.Close(); err != test.err {
+			t.Errorf("Close: want %v, got %v", test.err, err)
+		}
+	}
+}
+
+func TestAsyncWriterContext(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	dst := newGateWriter()
+	w := moreio.NewAsyncWriterContext(ctx, dst, 4)
+
+	w.Write([]byte("abcdefgh"))
+	<-dst.calls
+
+	done := make(chan error)
+	go func() {
+		_, err := w.Write([]byte("ij"))
+		done <- err
+	}()
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("Write: want context.Canceled, got %v", err)
+	}
+
+	// The write in progress completes, but nothing more is written.
+	close(dst.gate)
+	if err := w.Close(); err != context.Canceled {
+		t.Fatalf("Close: want context.Canceled, got %v", err)
+	}
+	if got := dst.String(); got != "abcd" {
+		t.Fatalf("incorrect output: want %q, got %q", "abcd", got)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package moreio
+
+import (
+	"errors"
+	"io"
+)
+
+var ErrWriteAfterClose = errors.New("write after close")
+
+// FooterWriter is returned by [NewFooterWriter].
+// See it's documentation for details.
+type FooterWriter struct {
+	w       io.Writer
+	footer  func() ([]byte, error)
+	written int64
+	closed  bool
+}
+
+// NewFooterWriter returns a writer that writes data to w,
+// and appends the footer returned by the footer function
+// when it's closed.
+//
+// The footer is typically computed from the written data,
+// like a checksum:
+//
+//	h := crc32.NewIEEE()
+//	fw := moreio.NewFooterWriter(w, func() ([]byte, error) {
+//		return h.Sum(nil), nil
+//	})
+//	io.Copy(io.MultiWriter(fw, h), src)
+//	fw.Close()
+//
+// [FooterWriter.Close] doesn't close w.
+// This is the counterpart of [NewFooterReader].
+func NewFooterWriter(w io.Writer, footer func() ([]byte, error)) *FooterWriter {
+	f := &FooterWriter{}
+	f.ResetWithFooter(w, footer)
+	return f
+}
+
+func (f *FooterWriter) Reset(w io.Writer) {
+	f.w = w
+	f.written = 0
+	f.closed = false
+}
+
+func (f *FooterWriter) ResetWithFooter(w io.Writer, footer func() ([]byte, error)) {
+	f.Reset(w)
+	f.footer = footer
+}
+
+func (f *FooterWriter) Write(b []byte) (int, error) {
+	if f.closed {
+		return 0, ErrWriteAfterClose
+	}
+	n, err := f.w.Write(b)
+	f.written += int64(n)
+	return n, err
+}
+
+// Written returns the number of bytes written,
+// not counting the footer.
+func (f *FooterWriter) Written() int64 {
+	return f.written
+}
+
+// Close writes the footer.
+// Subsequent writes return [ErrWriteAfterClose],
+// and subsequent calls to Close do nothing.
+func (f *FooterWriter) Close() error {
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+	footer, err := f.footer()
+	if err != nil {
+		return err
+	}
+	n, err := f.w.Write(footer)
+	if err == nil && n < len(footer) {
+		err = io.ErrShortWrite
+	}
+	return err
+}
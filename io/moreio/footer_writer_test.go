@@ -0,0 +1,77 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package moreio_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/layer8co/toolbox/io/moreio"
+)
+
+func TestFooterWriter(t *testing.T) {
+
+	var buf bytes.Buffer
+	h := crc32.NewIEEE()
+	w := moreio.NewFooterWriter(&buf, func() ([]byte, error) {
+		return h.Sum(nil), nil
+	})
+
+	io.Copy(io.MultiWriter(w, h), strings.NewReader("the quick brown fox"))
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("second Close: %v", err)
+	}
+	if n, err := w.Write([]byte("x")); n != 0 || err != moreio.ErrWriteAfterClose {
+		t.Fatalf("Write after Close: want 0, ErrWriteAfterClose, got %d, %v", n, err)
+	}
+	if w.Written() != 19 {
+		t.Fatalf("Written: want 19, got %d", w.Written())
+	}
+
+	// The stream round-trips through FooterReader.
+	r := moreio.NewFooterReader(&buf, make([]byte, crc32.Size))
+	b, err := io.ReadAll(r)
+	if string(b) != "the quick brown fox" || err != nil {
+		t.Fatalf("ReadAll: got %q, %v", b, err)
+	}
+	want := binary.BigEndian.AppendUint32(nil, crc32.ChecksumIEEE(b))
+	if !bytes.Equal(want, r.Footer()) {
+		t.Fatalf("incorrect footer: want %x, got %x", want, r.Footer())
+	}
+}
+
+func TestFooterWriterError(t *testing.T) {
+
+	errFooter := errors.New("footer failed")
+	var buf bytes.Buffer
+	w := moreio.NewFooterWriter(&buf, func() ([]byte, error) {
+		return nil, errFooter
+	})
+	w.Write([]byte("data"))
+	if err := w.Close(); err != errFooter {
+		t.Fatalf("Close: want %v, got %v", errFooter, err)
+	}
+	if buf.String() != "data" {
+		t.Fatalf("incorrect output: %q", buf.String())
+	}
+
+	w.ResetWithFooter(&buf, func() ([]byte, error) {
+		return []byte("!"), nil
+	})
+	w.Write([]byte("more"))
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close after ResetWithFooter: %v", err)
+	}
+	if buf.String() != "datamore!" {
+		t.Fatalf("incorrect output: %q", buf.String())
+	}
+}
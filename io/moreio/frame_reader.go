@@ -0,0 +1,164 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package moreio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// FrameReader is returned by [NewFrameReader].
+// See it's documentation for details.
+type FrameReader struct {
+	r        io.Reader
+	br       io.ByteReader
+	prefix   FramePrefix
+	maxFrame int
+	rem      int64 // The number of unread bytes of the current frame.
+	err      error // Sticky error that leaves the reader out of sync with r.
+	buf      [4]byte
+}
+
+// NewFrameReader returns a reader of the length-prefixed frames
+// written to r by a [FrameWriter] with the same prefix.
+//
+// Frames longer than maxFrame bytes are rejected with [ErrFrameTooLarge].
+// If maxFrame is not positive, frames are only limited by the prefix.
+//
+// The frames can be read whole using [FrameReader.ReadFrame],
+// or streamed using [FrameReader.Next] and [FrameReader.Read].
+//
+// FrameReader doesn't read from r beyond the frames it returns,
+// but reading the prefixes is faster if r implements [io.ByteReader].
+func NewFrameReader(r io.Reader, prefix FramePrefix, maxFrame int) *FrameReader {
+	f := &FrameReader{}
+	f.Reset(r)
+	f.prefix = prefix
+	f.maxFrame = maxFrame
+	return f
+}
+
+func (f *FrameReader) Reset(r io.Reader) {
+	f.r = r
+	if br, ok := r.(io.ByteReader); ok {
+		f.br = br
+	} else {
+		f.br = &byteReader{r: r}
+	}
+	f.rem = 0
+	f.err = nil
+}
+
+// Next advances to the next frame, discarding the rest of the current one,
+// and returns its length.
+//
+// It returns [io.EOF] if the stream ends cleanly before the next frame,
+// and [io.ErrUnexpectedEOF] if it ends within a frame.
+//
+// The payload of a frame rejected with [ErrFrameTooLarge] isn't skipped,
+// so the reader can't find the frames after it:
+// Next, Read and ReadFrame keep returning the error until [FrameReader.Reset].
+func (f *FrameReader) Next() (int, error) {
+
+	if f.err != nil {
+		return 0, f.err
+	}
+
+	if f.rem > 0 {
+		n, err := io.CopyN(io.Discard, f.r, f.rem)
+		f.rem -= n
+		if err == io.EOF {
+			return 0, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	var n uint64
+	var err error
+	switch f.prefix {
+	case FrameUvarint:
+		n, err = binary.ReadUvarint(f.br)
+	case FrameUint32:
+		_, err = io.ReadFull(f.r, f.buf[:4])
+		n = uint64(binary.BigEndian.Uint32(f.buf[:4]))
+	default:
+		panic("moreio.FrameReader: invalid prefix")
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if n > f.prefix.maxLen(f.maxFrame) {
+		f.err = fmt.Errorf("%w: %d bytes", ErrFrameTooLarge, n)
+		return 0, f.err
+	}
+
+	f.rem = int64(n)
+	return int(n), nil
+}
+
+// Read reads from the current frame.
+// It returns [io.EOF] at the end of the frame.
+func (f *FrameReader) Read(b []byte) (int, error) {
+	if f.err != nil {
+		return 0, f.err
+	}
+	if f.rem == 0 {
+		return 0, io.EOF
+	}
+	if int64(len(b)) > f.rem {
+		b = b[:f.rem]
+	}
+	n, err := f.r.Read(b)
+	f.rem -= int64(n)
+	if err == io.EOF {
+		if f.rem > 0 {
+			return n, io.ErrUnexpectedEOF
+		}
+		err = nil
+	}
+	return n, err
+}
+
+// ReadFrame reads the next frame into buf, growing it as needed,
+// and returns it.
+// Errors are like those of [FrameReader.Next].
+//
+// buf is grown as the frame is read rather than to the length in its prefix,
+// so that a corrupt or malicious prefix can't cause a huge allocation.
+func (f *FrameReader) ReadFrame(buf []byte) ([]byte, error) {
+	n, err := f.Next()
+	if err != nil {
+		return buf[:0], err
+	}
+	buf = buf[:0]
+	for len(buf) < n {
+		buf = slices.Grow(buf, min(n-len(buf), max(len(buf), 512)))
+		m, err := io.ReadFull(f, buf[len(buf):min(cap(buf), n)])
+		buf = buf[:len(buf)+m]
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return buf, err
+		}
+	}
+	return buf, nil
+}
+
+// byteReader adapts an [io.Reader] to an [io.ByteReader]
+// that doesn't read ahead.
+type byteReader struct {
+	r   io.Reader
+	buf [1]byte
+}
+
+func (b *byteReader) ReadByte() (byte, error) {
+	_, err := io.ReadFull(b.r, b.buf[:])
+	return b.buf[0], err
+}
@@ -0,0 +1,207 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package moreio_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/layer8co/toolbox/io/moreio"
+)
+
+func TestFrame(t *testing.T) {
+
+	tests := []struct {
+		line     int
+		prefix   moreio.FramePrefix
+		frames   []string
+		encoding string
+	}{
+		{
+			line(),
+			moreio.FrameUvarint,
+			[]string{"hello", "", "world"},
+			"\x05hello\x00\x05world",
+		},
+		{
+			line(),
+			moreio.FrameUvarint,
+			[]string{strings.Repeat("a", 200)},
+			"\xc8\x01" + strings.Repeat("a", 200),
+		},
+		{
+			line(),
+			moreio.FrameUint32,
+			[]string{"hello", ""},
+			"\x00\x00\x00\x05hello\x00\x00\x00\x00",
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("test%d-line%d", i, test.line), func(t *testing.T) {
+
+			var buf bytes.Buffer
+			w := moreio.NewFrameWriter(&buf, test.prefix, 0)
+			for _, f := range test.frames {
+				if err := w.WriteFrame([]byte(f)); err != nil {
+					t.Fatalf("WriteFrame: %v", err)
+				}
+			}
+			if test.encoding != buf.String() {
+				t.Errorf("incorrect encoding: want %q, got %q", test.encoding, buf.String())
+			}
+
+			r := moreio.NewFrameReader(iotest.OneByteReader(&buf), test.prefix, 0)
+			var got []string
+			var b []byte
+			for {
+				var err error
+				b, err = r.ReadFrame(b)
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("ReadFrame: %v", err)
+				}
+				got = append(got, string(b))
+			}
+			if diff := cmp.Diff(test.frames, got); diff != "" {
+				t.Errorf("incorrect result (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFrameReaderNext(t *testing.T) {
+
+	r := moreio.NewFrameReader(strings.NewReader("\x05hello\x03abc\x03de"), moreio.FrameUvarint, 0)
+
+	// Next skips the unread rest of the frame.
+	n, err := r.Next()
+	if n != 5 || err != nil {
+		t.Fatalf("Next: want 5, nil, got %d, %v", n, err)
+	}
+	b := make([]byte, 2)
+	if n, _ := r.Read(b); string(b[:n]) != "he" {
+		t.Fatalf("Read: want %q, got %q", "he", b[:n])
+	}
+	n, err = r.Next()
+	if n != 3 || err != nil {
+		t.Fatalf("Next: want 3, nil, got %d, %v", n, err)
+	}
+	if b, err := io.ReadAll(r); string(b) != "abc" || err != nil {
+		t.Fatalf("ReadAll: want %q, nil, got %q, %v", "abc", b, err)
+	}
+
+	// The frame is cut short by the end of the stream.
+	if _, err := r.Next(); err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if _, err := io.ReadAll(r); err != io.ErrUnexpectedEOF {
+		t.Fatalf("ReadAll: want ErrUnexpectedEOF, got %v", err)
+	}
+	if _, err := r.Next(); err != io.ErrUnexpectedEOF {
+		t.Fatalf("Next: want ErrUnexpectedEOF, got %v", err)
+	}
+}
+
+func TestFrameErrors(t *testing.T) {
+
+	tests := []struct {
+		line     int
+		prefix   moreio.FramePrefix
+		maxFrame int
+		stream   string
+		err      error
+	}{
+		{
+			line(),
+			moreio.FrameUvarint,
+			0,
+			"",
+			io.EOF,
+		},
+		{
+			line(),
+			moreio.FrameUvarint,
+			0,
+			"\x80",
+			io.ErrUnexpectedEOF,
+		},
+		{
+			line(),
+			moreio.FrameUvarint,
+			0,
+			"\x05hel",
+			io.ErrUnexpectedEOF,
+		},
+		{
+			line(),
+			moreio.FrameUint32,
+			0,
+			"\x00\x00",
+			io.ErrUnexpectedEOF,
+		},
+		{
+			line(),
+			moreio.FrameUvarint,
+			4,
+			"\x05hello",
+			moreio.ErrFrameTooLarge,
+		},
+		{
+			line(),
+			moreio.FrameUint32,
+			4,
+			"\x00\x00\x00\x05hello",
+			moreio.ErrFrameTooLarge,
+		},
+		{
+			line(),
+			moreio.FrameUint32,
+			0,
+			"\xff\xff\xff\xff",
+			io.ErrUnexpectedEOF,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("test%d-line%d", i, test.line), func(t *testing.T) {
+			r := moreio.NewFrameReader(strings.NewReader(test.stream), test.prefix, test.maxFrame)
+			_, err := r.ReadFrame(nil)
+			if !errors.Is(err, test.err) {
+				t.Errorf("incorrect error: want %v, got %v", test.err, err)
+			}
+		})
+	}
+
+	// The reader doesn't parse the payload of a rejected frame as a prefix.
+	r := moreio.NewFrameReader(strings.NewReader("\x05\x01\x01\x01\x01\x01"), moreio.FrameUvarint, 4)
+	for range 2 {
+		if n, err := r.Next(); n != 0 || !errors.Is(err, moreio.ErrFrameTooLarge) {
+			t.Errorf("Next: want 0, ErrFrameTooLarge, got %d, %v", n, err)
+		}
+	}
+	if _, err := r.Read(make([]byte, 1)); !errors.Is(err, moreio.ErrFrameTooLarge) {
+		t.Errorf("Read: want ErrFrameTooLarge, got %v", err)
+	}
+	r.Reset(strings.NewReader("\x01a"))
+	if b, err := r.ReadFrame(nil); string(b) != "a" || err != nil {
+		t.Errorf("ReadFrame after Reset: want \"a\", nil, got %q, %v", b, err)
+	}
+
+	w := moreio.NewFrameWriter(io.Discard, moreio.FrameUvarint, 4)
+	if err := w.WriteFrame([]byte("hello")); !errors.Is(err, moreio.ErrFrameTooLarge) {
+		t.Errorf("WriteFrame: want ErrFrameTooLarge, got %v", err)
+	}
+	if n, err := w.Write([]byte("hell")); n != 4 || err != nil {
+		t.Errorf("Write: want 4, nil, got %d, %v", n, err)
+	}
+}
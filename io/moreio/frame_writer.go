@@ -0,0 +1,99 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package moreio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+var ErrFrameTooLarge = errors.New("frame too large")
+
+// FramePrefix is the encoding of the length prefix of frames,
+// used by [FrameWriter] and [FrameReader].
+type FramePrefix uint8
+
+const (
+	// FrameUvarint prefixes frames with their length
+	// encoded as an unsigned varint (see [binary.AppendUvarint]).
+	FrameUvarint FramePrefix = iota
+
+	// FrameUint32 prefixes frames with their length
+	// encoded as a big-endian uint32.
+	FrameUint32
+)
+
+// maxLen returns the maximum frame length for the prefix and maxFrame.
+func (p FramePrefix) maxLen(maxFrame int) uint64 {
+	n := uint64(math.MaxInt)
+	if p == FrameUint32 {
+		n = math.MaxUint32
+	}
+	if maxFrame > 0 {
+		n = min(n, uint64(maxFrame))
+	}
+	return n
+}
+
+// FrameWriter is returned by [NewFrameWriter].
+// See it's documentation for details.
+type FrameWriter struct {
+	w        io.Writer
+	prefix   FramePrefix
+	maxFrame int
+	buf      [binary.MaxVarintLen64]byte
+}
+
+// NewFrameWriter returns a writer that writes length-prefixed frames to w,
+// which can be read using [NewFrameReader].
+//
+// Frames longer than maxFrame bytes are rejected with [ErrFrameTooLarge].
+// If maxFrame is not positive, frames are only limited by the prefix.
+func NewFrameWriter(w io.Writer, prefix FramePrefix, maxFrame int) *FrameWriter {
+	f := &FrameWriter{}
+	f.Reset(w)
+	f.prefix = prefix
+	f.maxFrame = maxFrame
+	return f
+}
+
+func (f *FrameWriter) Reset(w io.Writer) {
+	f.w = w
+}
+
+// WriteFrame writes b as a single frame.
+func (f *FrameWriter) WriteFrame(b []byte) error {
+
+	if uint64(len(b)) > f.prefix.maxLen(f.maxFrame) {
+		return fmt.Errorf("%w: %d bytes", ErrFrameTooLarge, len(b))
+	}
+
+	var prefix []byte
+	switch f.prefix {
+	case FrameUvarint:
+		prefix = binary.AppendUvarint(f.buf[:0], uint64(len(b)))
+	case FrameUint32:
+		prefix = binary.BigEndian.AppendUint32(f.buf[:0], uint32(len(b)))
+	default:
+		panic("moreio.FrameWriter: invalid prefix")
+	}
+
+	_, err := f.w.Write(prefix)
+	if err != nil || len(b) == 0 {
+		return err
+	}
+	_, err = f.w.Write(b)
+	return err
+}
+
+// Write writes b as a single frame; see [FrameWriter.WriteFrame].
+func (f *FrameWriter) Write(b []byte) (int, error) {
+	if err := f.WriteFrame(b); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
@@ -0,0 +1,82 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package moreio
+
+import (
+	"io"
+)
+
+// HeaderReader is returned by [NewHeaderReader].
+// See it's documentation for details.
+type HeaderReader struct {
+	r          io.Reader
+	header     []byte
+	headerSize int
+}
+
+// NewHeaderReader returns a reader that reads data from r,
+// excluding the first len(header) bytes of the stream,
+// which are read into header,
+// and can be retrieved via [HeaderReader.Header].
+//
+// The header is read by the first call to [HeaderReader.Read],
+// or explicitly via [HeaderReader.ReadHeader].
+// If the stream ends before the header is complete,
+// [io.ErrUnexpectedEOF] is returned.
+//
+// [HeaderReader.Read] is safe to call again
+// after errors (including EOF).
+func NewHeaderReader(r io.Reader, header []byte) *HeaderReader {
+	h := &HeaderReader{}
+	h.ResetWithHeader(r, header)
+	return h
+}
+
+func (h *HeaderReader) Reset(r io.Reader) {
+	h.r = r
+	h.headerSize = 0
+}
+
+func (h *HeaderReader) ResetWithHeader(r io.Reader, header []byte) {
+	h.r = r
+	h.header = header
+	h.headerSize = 0
+}
+
+// ReadHeader reads the header if it hasn't been read yet,
+// and returns it.
+func (h *HeaderReader) ReadHeader() ([]byte, error) {
+	i := 0
+	for h.headerSize != len(h.header) {
+		n, err := h.r.Read(h.header[h.headerSize:])
+		h.headerSize += n
+		if err == io.EOF {
+			return h.Header(), io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return h.Header(), err
+		}
+		if n == 0 {
+			i++
+		} else {
+			i = 0
+		}
+		if i >= maxConsecutiveEmptyReads {
+			return h.Header(), io.ErrNoProgress
+		}
+	}
+	return h.header, nil
+}
+
+func (h *HeaderReader) Read(b []byte) (int, error) {
+	if _, err := h.ReadHeader(); err != nil {
+		return 0, err
+	}
+	return h.r.Read(b)
+}
+
+// Header returns the bytes of the header that have been read so far.
+func (h *HeaderReader) Header() []byte {
+	return h.header[:h.headerSize]
+}
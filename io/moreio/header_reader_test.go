@@ -0,0 +1,121 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package moreio_test
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/layer8co/toolbox/io/moreio"
+)
+
+func TestHeaderReader(t *testing.T) {
+
+	tests := []struct {
+		line      int
+		headerLen int
+		stream    string
+		header    string
+		content   string
+		err       error
+	}{
+		{
+			line(),
+			4,
+			"the quick brown fox",
+			"the ",
+			"quick brown fox",
+			nil,
+		},
+		{
+			line(),
+			0,
+			"the quick",
+			"",
+			"the quick",
+			nil,
+		},
+		{
+			line(),
+			3,
+			"the",
+			"the",
+			"",
+			nil,
+		},
+		{
+			line(),
+			10,
+			"the quick",
+			"the quick",
+			"",
+			io.ErrUnexpectedEOF,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("test%d-line%d", i, test.line), func(t *testing.T) {
+
+			r := moreio.NewHeaderReader(
+				iotest.OneByteReader(strings.NewReader(test.stream)),
+				make([]byte, test.headerLen),
+			)
+
+			b, err := io.ReadAll(r)
+			content := string(b)
+
+			if test.content != content {
+				t.Errorf(
+					"incorrect content: want %q, got %q",
+					test.content, content,
+				)
+			}
+
+			if test.err != err {
+				t.Errorf(
+					"incorrect error: want %v, got %v",
+					test.err, err,
+				)
+			}
+
+			want := test.header
+			got := string(r.Header())
+			if want != got {
+				t.Errorf(
+					"incorrect header: want %q, got %q",
+					want, got,
+				)
+			}
+		})
+	}
+}
+
+func TestHeaderReaderReadHeader(t *testing.T) {
+
+	r := moreio.NewHeaderReader(strings.NewReader("v1:payload"), make([]byte, 3))
+
+	header, err := r.ReadHeader()
+	if string(header) != "v1:" || err != nil {
+		t.Fatalf("ReadHeader: want %q, nil, got %q, %v", "v1:", header, err)
+	}
+
+	// Reading the header again doesn't consume the stream.
+	header, err = r.ReadHeader()
+	if string(header) != "v1:" || err != nil {
+		t.Fatalf("ReadHeader: want %q, nil, got %q, %v", "v1:", header, err)
+	}
+
+	b, err := io.ReadAll(r)
+	if string(b) != "payload" || err != nil {
+		t.Fatalf("ReadAll: want %q, nil, got %q, %v", "payload", b, err)
+	}
+
+	r.Reset(strings.NewReader("v2:x"))
+	if header, _ := r.ReadHeader(); string(header) != "v2:" {
+		t.Fatalf("ReadHeader after Reset: want %q, got %q", "v2:", header)
+	}
+}
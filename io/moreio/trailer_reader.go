@@ -0,0 +1,162 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package moreio
+
+import (
+	"errors"
+	"io"
+)
+
+var ErrInvalidTrailer = errors.New("invalid trailer length")
+
+// trailerReadSize is the number of bytes [TrailerReader]
+// reads at a time beyond its window.
+const trailerReadSize = 4096
+
+// TrailerReader is returned by [NewTrailerReader].
+// See it's documentation for details.
+type TrailerReader struct {
+	r            io.Reader
+	maxTrailer   int
+	parseTrailer func(window []byte) (n int, err error)
+
+	buf []byte // Unread bytes are in buf[off:].
+	off int
+	end int // The end of the body in buf, once the trailer is parsed.
+
+	trailer []byte
+	err     error // Sticky error, set once the end of r is reached.
+}
+
+// NewTrailerReader returns a reader that reads data from r,
+// excluding a trailer of variable length at the end of the stream,
+// like a delimiter-terminated or self-describing footer.
+// The trailer can be retrieved via [TrailerReader.Trailer]
+// once [TrailerReader.Read] has returned [io.EOF].
+//
+// The trailer must be at most maxTrailer bytes long.
+// The reader holds back the last maxTrailer bytes it has read,
+// and only returns bytes once they're provably outside the trailer.
+// At the end of r, it calls parseTrailer with the held back window,
+// which is the whole stream if it's shorter than maxTrailer.
+// parseTrailer returns the length of the trailer at the end of the window,
+// and the rest of the window is returned as data.
+//
+// An error returned by parseTrailer is returned by [TrailerReader.Read],
+// and [ErrInvalidTrailer] is returned if the length is out of range.
+//
+// For example, for a stream with a JSON trailer
+// followed by its length as a 4-byte big-endian integer:
+//
+//	r := moreio.NewTrailerReader(r, 4+maxJSON, func(w []byte) (int, error) {
+//		if len(w) < 4 {
+//			return 0, io.ErrUnexpectedEOF
+//		}
+//		return 4 + int(binary.BigEndian.Uint32(w[len(w)-4:])), nil
+//	})
+//
+// [TrailerReader.Read] is safe to call again
+// after errors (including EOF).
+//
+// NewTrailerReader panics if maxTrailer is negative.
+func NewTrailerReader(r io.Reader, maxTrailer int, parseTrailer func(window []byte) (n int, err error)) *TrailerReader {
+	if maxTrailer < 0 {
+		panic("NewTrailerReader: maxTrailer < 0")
+	}
+	t := &TrailerReader{
+		maxTrailer:   maxTrailer,
+		parseTrailer: parseTrailer,
+	}
+	t.Reset(r)
+	return t
+}
+
+func (t *TrailerReader) Reset(r io.Reader) {
+	t.r = r
+	t.buf = t.buf[:0]
+	t.off = 0
+	t.end = 0
+	t.trailer = nil
+	t.err = nil
+}
+
+func (t *TrailerReader) Read(b []byte) (int, error) {
+	for {
+		if n := t.body(); n > 0 {
+			n = copy(b, t.buf[t.off:t.off+n])
+			t.off += n
+			return n, nil
+		}
+		if t.err != nil {
+			return 0, t.err
+		}
+		if err := t.fill(); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// body returns the number of unread bytes
+// that are known to be outside the trailer.
+func (t *TrailerReader) body() int {
+	if t.err != nil {
+		return t.end - t.off
+	}
+	return max(len(t.buf)-t.maxTrailer-t.off, 0)
+}
+
+// fill reads from r into buf until there are bytes outside the trailer,
+// or the trailer has been parsed.
+func (t *TrailerReader) fill() error {
+
+	// Only the window remains unread; move it to the front.
+	t.buf = t.buf[:copy(t.buf, t.buf[t.off:])]
+	t.off = 0
+
+	if cap(t.buf) < t.maxTrailer+trailerReadSize {
+		buf := make([]byte, len(t.buf), t.maxTrailer+trailerReadSize)
+		copy(buf, t.buf)
+		t.buf = buf
+	}
+
+	for i := 0; i < maxConsecutiveEmptyReads; i++ {
+		n, err := t.r.Read(t.buf[len(t.buf):cap(t.buf)])
+		t.buf = t.buf[:len(t.buf)+n]
+		if err == io.EOF {
+			return t.parse()
+		}
+		if n > 0 || err != nil {
+			return err
+		}
+	}
+	return io.ErrNoProgress
+}
+
+// parse parses the trailer at the end of r.
+// It sets the sticky error, so that the trailer is only parsed once.
+func (t *TrailerReader) parse() error {
+
+	// The last read may have returned bytes along with io.EOF,
+	// so the window may not be all of buf.
+	window := t.buf[max(len(t.buf)-t.maxTrailer, 0):]
+
+	t.err = io.EOF
+	n, err := t.parseTrailer(window)
+	switch {
+	case err != nil:
+		t.err = err
+	case n < 0 || n > len(window):
+		t.err = ErrInvalidTrailer
+	default:
+		t.end = len(t.buf) - n
+		t.trailer = t.buf[t.end:]
+	}
+	return nil
+}
+
+// Trailer returns the trailer,
+// or nil if the end of the stream hasn't been reached yet.
+func (t *TrailerReader) Trailer() []byte {
+	return t.trailer
+}
@@ -0,0 +1,221 @@
+// Copyright 2025 the toolbox authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package moreio_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/layer8co/toolbox/io/moreio"
+)
+
+// parseLenTrailer parses a trailer followed by its length
+// as a 4-byte big-endian integer, which counts itself.
+func parseLenTrailer(w []byte) (int, error) {
+	if len(w) < 4 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	return int(binary.BigEndian.Uint32(w[len(w)-4:])), nil
+}
+
+func lenTrailer(s string) string {
+	return string(binary.BigEndian.AppendUint32([]byte(s), uint32(len(s)+4)))
+}
+
+// parseLineTrailer parses the last line of the window as the trailer.
+func parseLineTrailer(w []byte) (int, error) {
+	i := bytes.LastIndexByte(w, '\n')
+	if i < 0 {
+		return 0, errors.New("no trailer line")
+	}
+	return len(w) - i, nil
+}
+
+func TestTrailerReader(t *testing.T) {
+
+	errNoTrailer := errors.New("no trailer line")
+
+	tests := []struct {
+		line       int
+		maxTrailer int
+		parse      func([]byte) (int, error)
+		stream     string
+		content    string
+		trailer    string
+		err        error
+	}{
+		{
+			line(),
+			20,
+			parseLenTrailer,
+			"the quick brown fox" + lenTrailer(`{"a":1}`),
+			"the quick brown fox",
+			lenTrailer(`{"a":1}`),
+			io.EOF,
+		},
+		{
+			line(),
+			11,
+			parseLenTrailer,
+			"the quick brown fox" + lenTrailer(`{"a":1}`),
+			"the quick brown fox",
+			lenTrailer(`{"a":1}`),
+			io.EOF,
+		},
+		{
+			// The stream is shorter than the window.
+			line(),
+			100,
+			parseLenTrailer,
+			"fox" + lenTrailer(""),
+			"fox",
+			lenTrailer(""),
+			io.EOF,
+		},
+		{
+			line(),
+			100,
+			parseLenTrailer,
+			lenTrailer("only"),
+			"",
+			lenTrailer("only"),
+			io.EOF,
+		},
+		{
+			line(),
+			10,
+			parseLineTrailer,
+			strings.Repeat("body ", 2000) + "\nend",
+			strings.Repeat("body ", 2000),
+			"\nend",
+			io.EOF,
+		},
+		{
+			// The trailer is longer than the window.
+			line(),
+			10,
+			parseLenTrailer,
+			"the quick brown fox" + lenTrailer(`{"a":1}`),
+			"the quick brown fox{",
+			"",
+			moreio.ErrInvalidTrailer,
+		},
+		{
+			line(),
+			10,
+			parseLineTrailer,
+			"the quick brown fox",
+			"the quick",
+			"",
+			errNoTrailer,
+		},
+		{
+			line(),
+			10,
+			parseLenTrailer,
+			"",
+			"",
+			"",
+			io.ErrUnexpectedEOF,
+		},
+		{
+			line(),
+			0,
+			func([]byte) (int, error) { return 0, nil },
+			"the quick brown fox",
+			"the quick brown fox",
+			"",
+			io.EOF,
+		},
+	}
+
+	for i, test := range tests {
+		for _, oneByte := range []bool{false, true} {
+			t.Run(fmt.Sprintf("test%d-line%d-%t", i, test.line, oneByte), func(t *testing.T) {
+
+				var src io.Reader = strings.NewReader(test.stream)
+				if oneByte {
+					src = iotest.OneByteReader(src)
+				}
+				r := moreio.NewTrailerReader(src, test.maxTrailer, test.parse)
+
+				var content []byte
+				b := make([]byte, 7)
+				var err error
+				for err == nil {
+					var n int
+					n, err = r.Read(b)
+					content = append(content, b[:n]...)
+				}
+
+				if test.content != string(content) {
+					t.Errorf(
+						"incorrect content: want %q, got %q",
+						test.content, content,
+					)
+				}
+
+				if test.err.Error() != err.Error() {
+					t.Errorf(
+						"incorrect error: want %v, got %v",
+						test.err, err,
+					)
+				}
+
+				want := test.trailer
+				got := string(r.Trailer())
+				if want != got {
+					t.Errorf(
+						"incorrect trailer: want %q, got %q",
+						want, got,
+					)
+				}
+
+				// The error is sticky.
+				if n, err2 := r.Read(b); n != 0 || err2 != err {
+					t.Errorf("Read after %v: got %d, %v", err, n, err2)
+				}
+			})
+		}
+	}
+}
+
+func TestTrailerReaderRandom(t *testing.T) {
+
+	r := moreio.NewTrailerReader(nil, 64, parseLenTrailer)
+
+	for seed := range uint64(100) {
+
+		rnd := rand.New(rand.NewPCG(seed, seed))
+
+		body := make([]byte, rnd.IntN(20000))
+		for i := range body {
+			body[i] = byte(rnd.Uint32())
+		}
+		trailer := lenTrailer(strings.Repeat("x", rnd.IntN(61)))
+
+		var src io.Reader = strings.NewReader(string(body) + trailer)
+		switch rnd.IntN(3) {
+		case 1:
+			src = iotest.HalfReader(src)
+		case 2:
+			src = iotest.DataErrReader(src)
+		}
+
+		r.Reset(src)
+		if err := iotest.TestReader(r, body); err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+		if string(r.Trailer()) != trailer {
+			t.Fatalf("seed %d: incorrect trailer: want %q, got %q", seed, trailer, r.Trailer())
+		}
+	}
+}